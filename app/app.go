@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"taxi-fare-calculator/config"
+	"taxi-fare-calculator/database"
+	"taxi-fare-calculator/dataset"
+	"taxi-fare-calculator/fares"
+	"taxi-fare-calculator/graph"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/planner"
+	"taxi-fare-calculator/roads"
+	"taxi-fare-calculator/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// App holds the dependencies shared by the handlers and commands
+type App struct {
+	Config     *config.Config
+	DB         *mongo.Database // nil when using in-memory storage
+	Stations   models.StationStore
+	Routes     models.RouteStore
+	Tariffs    models.TariffStore
+	Surcharges models.SurchargeStore
+	Events     models.FareEventStore
+	Graph      *graph.Cache
+	Fares      *fares.Engine
+	History    *fares.History
+	Planner    *planner.Planner
+	Router     utils.Router
+	Roads      *roads.Cache
+	Mailer     utils.Mailer
+}
+
+// New wires up the application from the given configuration
+func New(cfg *config.Config) (*App, error) {
+	a := &App{
+		Config: cfg,
+		Mailer: utils.NewResendMailer(cfg.ResendAPIKey, cfg.MailFrom),
+	}
+	router, err := newRouter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.Router = router
+
+	if cfg.Storage == "memory" {
+		log.Printf("⚠️ Using in-memory storage, data will not be persisted")
+		store := database.NewMemoryStore()
+		a.setStores(store, store, store, store, store, store, store)
+		if err := a.seedSurcharges(); err != nil {
+			return nil, err
+		}
+		if cfg.SeedFile != "" {
+			if err := a.seed(cfg.SeedFile); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	}
+
+	if err := connectDB(cfg.MongoURI); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Using database %s", cfg.DatabaseName)
+	a.DB = database.GetDatabase(cfg.DatabaseName)
+	if cfg.MigrateOnStart {
+		if err := migrate(a.DB); err != nil {
+			return nil, err
+		}
+	}
+	store := database.NewMongoStore(a.DB)
+	a.setStores(store, store, store, store, store, store, store)
+	if err := a.seedSurcharges(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// setStores installs the stores behind the route graph cache so writes invalidate
+// it, behind the price history so route writes are versioned, and behind the
+// road leg cache so moved stations are routed again
+func (a *App) setStores(stations models.StationStore, routes models.RouteStore, tariffs models.TariffStore, prices models.PriceHistoryStore, surcharges models.SurchargeStore, events models.FareEventStore, roadLegs models.RoadLegStore) {
+	a.Graph = graph.NewCache(stations, routes, a.Config.GraphMaxAge, graph.Options{
+		TransferPenalty: a.Config.TransferPenalty,
+	})
+	a.Roads = roads.NewCache(a.Router, roadLegs, a.Config.RoadCacheTTL)
+	a.Stations = a.Graph.WrapStations(a.Roads.WrapStations(stations))
+	a.History = fares.NewHistory(routes, prices)
+	a.Routes = a.Graph.WrapRoutes(a.History.WrapRoutes(routes))
+	a.Tariffs = tariffs
+	a.Fares = fares.NewEngine(tariffs, surcharges, events, a.Config.FareRounding)
+	a.Surcharges = surcharges
+	a.Events = events
+	a.Planner = planner.New(a.Graph, a.Fares, a.History, a.Roads, a.Stations, planner.Options{
+		Candidates:   a.Config.SnapCandidates,
+		MaxWalk:      a.Config.MaxWalk,
+		WalkPenalty:  a.Config.WalkPenalty,
+		DetourFactor: a.Config.DetourFactor,
+	})
+}
+
+// newRouter chains the configured road routers, ending with straight lines
+// stretched by the detour factor so a route is always found
+func newRouter(cfg *config.Config) (utils.Router, error) {
+	var routers utils.FallbackRouter
+	if cfg.OSRMURL != "" {
+		routers = append(routers, utils.NewOSRMRouter(cfg.OSRMURL, cfg.OSRMTimeout, cfg.OSRMRetries))
+	}
+	if cfg.RoadNetworkFile != "" {
+		local, err := utils.LoadLocalRouter(cfg.RoadNetworkFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading road network %s: %v", cfg.RoadNetworkFile, err)
+		}
+		log.Printf("✅ Loaded road network from %s", cfg.RoadNetworkFile)
+		routers = append(routers, local)
+	}
+	return append(routers, utils.StraightLineRouter{DetourFactor: cfg.DetourFactor}), nil
+}
+
+// migrate applies pending migrations and makes sure the required indexes exist
+func migrate(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	applied, err := database.Migrate(ctx, db)
+	if err != nil {
+		return fmt.Errorf("error migrating database: %v", err)
+	}
+	if len(applied) > 0 {
+		log.Printf("✅ Applied %d migration(s)", len(applied))
+	}
+	return nil
+}
+
+// connectDB connects to MongoDB with retries
+func connectDB(mongoURI string) error {
+	maxRetries := 3
+	var err error
+	for i := 0; i < maxRetries; i++ {
+		err = database.ConnectDB(mongoURI)
+		if err == nil {
+			return nil
+		}
+		log.Printf("❌ Failed to connect to MongoDB (attempt %d/%d): %v", i+1, maxRetries, err)
+		if i < maxRetries-1 {
+			log.Printf("Retrying in 5 seconds...")
+			time.Sleep(5 * time.Second)
+		}
+	}
+	return fmt.Errorf("failed to initialize database after %d attempts: %v", maxRetries, err)
+}
+
+// seed imports a dataset file into the stores
+func (a *App) seed(path string) error {
+	bundle, err := dataset.LoadFile(path)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	report, err := dataset.Import(ctx, a.Stations, a.Routes, bundle, dataset.ImportOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to seed from %s: %v %v", path, err, append(report.Errors, report.Conflicts...))
+	}
+	log.Printf("🌱 Seeded %d stations and %d routes from %s", len(report.StationsCreated), len(report.RoutesCreated), path)
+	return nil
+}
+
+// seedSurcharges stores the default surcharge rules in a database without any
+func (a *App) seedSurcharges() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := a.Fares.SeedSurcharges(ctx); err != nil {
+		return fmt.Errorf("failed to seed the default surcharges: %v", err)
+	}
+	return nil
+}
+
+// Close releases the resources held by the application
+func (a *App) Close() {
+	if a.DB != nil {
+		database.DisconnectDB()
+	}
+}
@@ -0,0 +1,92 @@
+// Package calendar converts between the Ethiopian and Gregorian calendars and
+// lists Ethiopian public holidays that fall on fixed dates.
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// MonthNames are the thirteen Ethiopian months; Pagume has 5 days, or 6 in
+// the year before a Gregorian leap year
+var MonthNames = [13]string{
+	"Meskerem", "Tikimt", "Hidar", "Tahsas", "Tir", "Yekatit",
+	"Megabit", "Miazia", "Ginbot", "Sene", "Hamle", "Nehase", "Pagume",
+}
+
+// ethiopianEpoch is the Julian day number offset of the Amete Mihret era used
+// by the standard conversion
+const ethiopianEpoch = 1723856
+
+// unixEpochJDN is the Julian day number of 1970-01-01
+const unixEpochJDN = 2440588
+
+// Date is a date in the Ethiopian calendar
+type Date struct {
+	Year  int `json:"year"`
+	Month int `json:"month"` // 1-13
+	Day   int `json:"day"`
+}
+
+// IsLeapYear reports whether an Ethiopian year has a 6 day Pagume
+func IsLeapYear(year int) bool {
+	return year%4 == 3
+}
+
+// NewDate returns the Ethiopian date, validating the month and day
+func NewDate(year, month, day int) (Date, error) {
+	if month < 1 || month > 13 || day < 1 || day > 30 {
+		return Date{}, fmt.Errorf("invalid Ethiopian date %d-%d-%d", year, month, day)
+	}
+	if month == 13 && (day > 6 || (day == 6 && !IsLeapYear(year))) {
+		return Date{}, fmt.Errorf("invalid Ethiopian date %d-%d-%d: Pagume is too short", year, month, day)
+	}
+	return Date{Year: year, Month: month, Day: day}, nil
+}
+
+// Parse parses an Ethiopian date formatted as YYYY-MM-DD
+func Parse(value string) (Date, error) {
+	var year, month, day int
+	if _, err := fmt.Sscanf(value, "%d-%d-%d", &year, &month, &day); err != nil {
+		return Date{}, fmt.Errorf("invalid Ethiopian date %q", value)
+	}
+	return NewDate(year, month, day)
+}
+
+// FromGregorian returns the Ethiopian date of the calendar day of t in its location
+func FromGregorian(t time.Time) Date {
+	jdn := gregorianJDN(t)
+	r := (jdn - ethiopianEpoch) % 1461
+	n := r%365 + 365*(r/1460)
+
+	return Date{
+		Year:  4*((jdn-ethiopianEpoch)/1461) + r/365 - r/1460,
+		Month: n/30 + 1,
+		Day:   n%30 + 1,
+	}
+}
+
+// Gregorian returns midnight UTC of the Gregorian day matching the date
+func (d Date) Gregorian() time.Time {
+	jdn := ethiopianEpoch + 365 + 365*(d.Year-1) + d.Year/4 + 30*d.Month + d.Day - 31
+	return time.Unix(int64(jdn-unixEpochJDN)*24*60*60, 0).UTC()
+}
+
+// String formats the date as YYYY-MM-DD
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Format spells the date out, e.g. "Meskerem 17, 2017"
+func (d Date) Format() string {
+	if d.Month < 1 || d.Month > 13 {
+		return d.String()
+	}
+	return fmt.Sprintf("%s %d, %d", MonthNames[d.Month-1], d.Day, d.Year)
+}
+
+func gregorianJDN(t time.Time) int {
+	year, month, day := t.Date()
+	days := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+	return int(days) + unixEpochJDN
+}
@@ -0,0 +1,57 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGregorianConversion(t *testing.T) {
+	tests := []struct {
+		name      string
+		ethiopian Date
+		gregorian string
+	}{
+		{"Meskel 2017", Date{Year: 2017, Month: 1, Day: 17}, "2024-09-27"},
+		{"Enkutatash 2017", Date{Year: 2017, Month: 1, Day: 1}, "2024-09-11"},
+		{"Enkutatash after a 6 day Pagume", Date{Year: 2016, Month: 1, Day: 1}, "2023-09-12"},
+		{"Pagume 6 of a leap year", Date{Year: 2015, Month: 13, Day: 6}, "2023-09-11"},
+		{"Genna 2017", Date{Year: 2017, Month: 4, Day: 29}, "2025-01-07"},
+		{"Gregorian leap day", Date{Year: 2016, Month: 6, Day: 21}, "2024-02-29"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ethiopian.Gregorian().Format("2006-01-02"); got != tt.gregorian {
+				t.Errorf("%s.Gregorian() = %s, want %s", tt.ethiopian, got, tt.gregorian)
+			}
+			gregorian, _ := time.Parse("2006-01-02", tt.gregorian)
+			if got := FromGregorian(gregorian); got != tt.ethiopian {
+				t.Errorf("FromGregorian(%s) = %s, want %s", tt.gregorian, got, tt.ethiopian)
+			}
+		})
+	}
+}
+
+func TestHolidaysOn(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2024-09-27", "Meskel"},
+		{"2024-09-11", "Enkutatash"},
+		{"2025-01-07", "Genna"},
+		{"2025-05-01", "International Workers' Day"},
+		{"2024-09-28", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			holidays := HolidaysOn(tt.date)
+			var got string
+			if len(holidays) > 0 {
+				got = holidays[0].Name
+			}
+			if got != tt.want {
+				t.Errorf("HolidaysOn(%s) = %q, want %q", tt.date, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package calendar
+
+import "time"
+
+// Holiday is a public holiday on a fixed Ethiopian date
+type Holiday struct {
+	Name      string `json:"name"`
+	Ethiopian Date   `json:"ethiopian"`
+	Date      string `json:"date"` // Gregorian YYYY-MM-DD
+}
+
+// fixedHolidays are the public holidays that fall on the same Ethiopian date
+// every year. Holidays following the lunar or Easter calendars are not
+// included and should be registered as holidays or fare events.
+var fixedHolidays = []struct {
+	name       string
+	month, day int
+}{
+	{"Enkutatash", 1, 1},
+	{"Meskel", 1, 17},
+	{"Genna", 4, 29},
+	{"Timket", 5, 11},
+	{"Adwa Victory Day", 6, 23},
+	{"Patriots' Victory Day", 8, 27},
+	{"Downfall of the Derg", 9, 20},
+}
+
+// Holidays returns the fixed public holidays of an Ethiopian year
+func Holidays(year int) []Holiday {
+	holidays := make([]Holiday, 0, len(fixedHolidays)+1)
+	for _, fixed := range fixedHolidays {
+		date := Date{Year: year, Month: fixed.month, Day: fixed.day}
+		if fixed.name == "Genna" && IsLeapYear(year-1) {
+			// Genna stays on 7 January, which is Tahsas 28 after a 6 day Pagume
+			date.Day = 28
+		}
+		holidays = append(holidays, newHoliday(fixed.name, date))
+	}
+
+	// International Workers' Day follows the Gregorian calendar
+	start := Date{Year: year, Month: 1, Day: 1}.Gregorian()
+	mayDay := time.Date(start.Year()+1, time.May, 1, 0, 0, 0, 0, time.UTC)
+	holidays = append(holidays, newHoliday("International Workers' Day", FromGregorian(mayDay)))
+	return holidays
+}
+
+// HolidaysOn returns the fixed public holidays on a Gregorian date (YYYY-MM-DD)
+func HolidaysOn(date string) []Holiday {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil
+	}
+	var found []Holiday
+	for _, holiday := range Holidays(FromGregorian(t).Year) {
+		if holiday.Date == date {
+			found = append(found, holiday)
+		}
+	}
+	return found
+}
+
+func newHoliday(name string, date Date) Holiday {
+	return Holiday{
+		Name:      name,
+		Ethiopian: date,
+		Date:      date.Gregorian().Format("2006-01-02"),
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"taxi-fare-calculator/app"
+	"taxi-fare-calculator/config"
+	"taxi-fare-calculator/database"
+	"taxi-fare-calculator/dataset"
+	"taxi-fare-calculator/gtfs"
+	"taxi-fare-calculator/models"
+	"time"
+)
+
+// runCommand executes a CLI subcommand such as "import" or "export"
+func runCommand(cfg *config.Config, name string, args []string) error {
+	switch name {
+	case "import":
+		return runImport(cfg, args)
+	case "export":
+		return runExport(cfg, args)
+	case "import-gtfs":
+		return runImportGTFS(cfg, args)
+	case "migrate":
+		return runMigrate(cfg, args)
+	default:
+		return fmt.Errorf("unknown command %q (available: import, export, import-gtfs, migrate)", name)
+	}
+}
+
+func runImport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "validate and report changes without writing")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import [--dry-run] FILE...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no dataset files given")
+	}
+
+	bundle := &dataset.Bundle{}
+	for _, path := range flags.Args() {
+		b, err := dataset.LoadFile(path)
+		if err != nil {
+			return err
+		}
+		bundle.Merge(b)
+	}
+
+	if cfg.Storage == "memory" {
+		log.Printf("⚠️ Importing into in-memory storage, nothing will be persisted")
+	}
+	a, err := app.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report, err := dataset.Import(ctx, a.Stations, a.Routes, bundle, dataset.ImportOptions{DryRun: *dryRun})
+	if report != nil {
+		// The report explains a failed import, so the import error comes first
+		if printErr := printJSON(report); err == nil {
+			err = printErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		log.Printf("✅ Dry run complete, no changes written")
+	} else {
+		log.Printf("✅ Import complete")
+	}
+	return nil
+}
+
+func runExport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "write the bundle to this file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s export [-o FILE]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	a, err := app.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	bundle, err := dataset.Export(ctx, a.Stations, a.Routes)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Exported %d stations and %d routes (%s)", len(bundle.Stations), len(bundle.Routes), bundle.Hash)
+	return nil
+}
+
+func runImportGTFS(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import-gtfs", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "validate and report changes without writing")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import-gtfs [--dry-run] FEED.zip\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected exactly one GTFS zip file")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	a, err := app.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	tariff, err := a.Fares.TariffAt(ctx, models.VehicleMinibus, time.Now())
+	if err != nil {
+		return err
+	}
+
+	bundle, conversion, err := gtfs.ReadFeed(file, info.Size(), tariff.Fare)
+	if err != nil {
+		return err
+	}
+
+	report, err := dataset.Import(ctx, a.Stations, a.Routes, bundle, dataset.ImportOptions{DryRun: *dryRun})
+	printErr := printJSON(map[string]interface{}{
+		"conversion": conversion,
+		"import":     report,
+	})
+	if err == nil {
+		err = printErr
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✅ Imported GTFS feed with %d stations and %d routes", len(bundle.Stations), len(bundle.Routes))
+	return nil
+}
+
+func runMigrate(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	status := flags.Bool("status", false, "list applied and pending migrations without applying them")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s migrate [--status]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if cfg.Storage == "memory" {
+		return errors.New("migrations only apply to MongoDB storage")
+	}
+	// Connect without migrating so --status reports the database as it is
+	cfg.MigrateOnStart = false
+	a, err := app.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if *status {
+		applied, err := database.AppliedMigrations(ctx, a.DB)
+		if err != nil {
+			return err
+		}
+		pending, err := database.PendingMigrations(ctx, a.DB)
+		if err != nil {
+			return err
+		}
+		for _, migration := range applied {
+			fmt.Printf("applied  %3d  %s  %s\n", migration.Version, migration.AppliedAt.Format(time.RFC3339), migration.Description)
+		}
+		for _, migration := range pending {
+			fmt.Printf("pending  %3d  %-20s  %s\n", migration.Version, "", migration.Description)
+		}
+		return nil
+	}
+
+	applied, err := database.Migrate(ctx, a.DB)
+	for _, migration := range applied {
+		log.Printf("Applied migration %d: %s", migration.Version, migration.Description)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Database is up to date, %d migration(s) applied", len(applied))
+	return nil
+}
+
+// printJSON writes a report to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"taxi-fare-calculator/models"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +14,42 @@ type Config struct {
 	MongoURI     string
 	DatabaseName string
 	Port         string
+	Storage      string // "mongo" or "memory"
+	SeedFile     string // dataset imported into in-memory storage at startup
+	GraphMaxAge  time.Duration
+	// MigrateOnStart applies pending migrations and creates the required
+	// indexes when the server starts; otherwise run the migrate command
+	MigrateOnStart bool
+	// TransferPenalty is the routing cost in Birr of changing vehicles
+	TransferPenalty float64
+	// FareRounding is the step fares are rounded to, as drivers charge them
+	FareRounding models.Money
+	// DetourFactor stretches straight-line distances into road distances when
+	// no router can be reached
+	DetourFactor float64
+
+	// OSRMURL is the OSRM server used for road routes; empty disables it
+	OSRMURL     string
+	OSRMTimeout time.Duration
+	OSRMRetries int
+	// RoadNetworkFile is a GeoJSON road network routed over when OSRM fails
+	RoadNetworkFile string
+	// RoadCacheTTL is how long road routes between stations are kept
+	RoadCacheTTL time.Duration
+
+	// Door-to-door planning: how many nearby stations are tried at each end,
+	// the longest walk in meters and the cost in Birr of a minute of walking
+	SnapCandidates int
+	MaxWalk        float64
+	WalkPenalty    float64
+
+	// AdminToken guards the admin API; admin endpoints are disabled when empty
+	AdminToken string
+
+	ResendAPIKey  string
+	MailFrom      string
+	AdminEmail    string
+	CloudinaryURL string
 }
 
 func LoadConfig() *Config {
@@ -23,6 +62,32 @@ func LoadConfig() *Config {
 		MongoURI:     getEnv("MONGO_URI", "mongodb://localhost:27017"),
 		DatabaseName: getEnv("DB_NAME", "taxi_fare_db"),
 		Port:         getEnv("PORT", "8080"),
+		Storage:      getEnv("STORAGE", "mongo"),
+		SeedFile:     getEnv("SEED_FILE", ""),
+		GraphMaxAge:  getEnvDuration("GRAPH_MAX_AGE", 5*time.Minute),
+
+		MigrateOnStart: getEnvBool("MIGRATE_ON_START", true),
+
+		TransferPenalty: getEnvFloat("TRANSFER_PENALTY", 5),
+		FareRounding:    models.FromBirr(getEnvFloat("FARE_ROUNDING", 0.5)),
+		DetourFactor:    getEnvFloat("DETOUR_FACTOR", 1.4),
+
+		OSRMURL:         getEnv("OSRM_URL", "http://router.project-osrm.org"),
+		OSRMTimeout:     getEnvDuration("OSRM_TIMEOUT", 5*time.Second),
+		OSRMRetries:     getEnvInt("OSRM_RETRIES", 2),
+		RoadNetworkFile: getEnv("ROAD_NETWORK_FILE", ""),
+		RoadCacheTTL:    getEnvDuration("ROAD_CACHE_TTL", 30*24*time.Hour),
+
+		SnapCandidates: getEnvInt("SNAP_CANDIDATES", 3),
+		MaxWalk:        getEnvFloat("MAX_WALK", 1500),
+		WalkPenalty:    getEnvFloat("WALK_PENALTY", 1),
+
+		AdminToken: getEnv("ADMIN_TOKEN", ""),
+
+		ResendAPIKey:  getEnv("RESEND_API_KEY", ""),
+		MailFrom:      getEnv("MAIL_FROM", "Redat Contributions <onboarding@resend.dev>"),
+		AdminEmail:    getEnv("ADMIN_EMAIL", ""),
+		CloudinaryURL: getEnv("CLOUDINARY_URL", ""),
 	}
 }
 
@@ -32,3 +97,55 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid duration %q for %s, using %s", value, key, fallback)
+		return fallback
+	}
+	return d
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Warning: invalid number %q for %s, using %g", value, key, fallback)
+		return fallback
+	}
+	return f
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid integer %q for %s, using %d", value, key, fallback)
+		return fallback
+	}
+	return i
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid boolean %q for %s, using %t", value, key, fallback)
+		return fallback
+	}
+	return b
+}
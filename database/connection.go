@@ -41,11 +41,6 @@ func ConnectDB(mongoURI string) error {
 	return nil
 }
 
-func GetCollection(dbName string, collectionName string) *mongo.Collection {
-	collection := mongoClient.Database(dbName).Collection(collectionName)
-	return collection
-}
-
 func DisconnectDB() {
 	if mongoClient != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -58,3 +53,7 @@ func DisconnectDB() {
 		log.Println("✅ Successfully disconnected from MongoDB")
 	}
 }
+
+func GetDatabase(dbName string) *mongo.Database {
+	return mongoClient.Database(dbName)
+}
@@ -0,0 +1,679 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryStore implements every store interface of the models package, from
+// StationStore to RoadLegStore, in process memory.
+// It is meant for tests, CI and local development without a MongoDB server.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	stations   []models.Station
+	routes     []models.Route
+	tariffs    []models.Tariff
+	prices     []models.PriceRecord
+	surcharges []models.Surcharge
+	holidays   []models.Holiday
+	events     []models.FareEvent
+	roadLegs   []models.RoadLeg
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func cloneStation(s models.Station) models.Station {
+	s.Location.Coordinates = append([]float64(nil), s.Location.Coordinates...)
+	s.ConnectedRoutes = append([]string(nil), s.ConnectedRoutes...)
+	if s.Aliases != nil {
+		s.Aliases = append([]models.Alias(nil), s.Aliases...)
+	}
+	return s
+}
+
+func cloneRoute(r models.Route) models.Route {
+	if r.IntermediateStations != nil {
+		r.IntermediateStations = append([]string(nil), r.IntermediateStations...)
+	}
+	if r.Services != nil {
+		r.Services = append([]models.Service(nil), r.Services...)
+	}
+	return r
+}
+
+func (s *MemoryStore) stationIndex(id primitive.ObjectID) int {
+	for i := range s.stations {
+		if s.stations[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// stationTaken reports whether a station other than id has the name or slug of station
+func (s *MemoryStore) stationTaken(station *models.Station, id primitive.ObjectID) bool {
+	for i := range s.stations {
+		if s.stations[i].ID == id {
+			continue
+		}
+		if s.stations[i].Name == station.Name || (station.Slug != "" && s.stations[i].Slug == station.Slug) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeTaken reports whether a route other than id goes from -> to
+func (s *MemoryStore) routeTaken(from, to string, id primitive.ObjectID) bool {
+	for i := range s.routes {
+		if s.routes[i].From == from && s.routes[i].To == to && s.routes[i].ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) routeIndex(id primitive.ObjectID) int {
+	for i := range s.routes {
+		if s.routes[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *MemoryStore) ListStations(ctx context.Context) ([]models.Station, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stations := make([]models.Station, 0, len(s.stations))
+	for _, station := range s.stations {
+		stations = append(stations, cloneStation(station))
+	}
+	return stations, nil
+}
+
+func (s *MemoryStore) GetStation(ctx context.Context, id primitive.ObjectID) (*models.Station, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.stationIndex(id)
+	if i < 0 {
+		return nil, models.ErrNotFound
+	}
+	station := cloneStation(s.stations[i])
+	return &station, nil
+}
+
+func (s *MemoryStore) GetStationByName(ctx context.Context, name string) (*models.Station, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, station := range s.stations {
+		if station.Name == name {
+			station = cloneStation(station)
+			return &station, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (s *MemoryStore) InsertStation(ctx context.Context, station *models.Station) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stationTaken(station, primitive.NilObjectID) {
+		return models.ErrDuplicate
+	}
+	if station.ID.IsZero() {
+		station.ID = primitive.NewObjectID()
+	}
+	s.stations = append(s.stations, cloneStation(*station))
+	return nil
+}
+
+func (s *MemoryStore) UpdateStation(ctx context.Context, id primitive.ObjectID, station *models.Station) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.stationIndex(id)
+	if i < 0 {
+		return models.ErrNotFound
+	}
+	if s.stationTaken(station, id) {
+		return models.ErrDuplicate
+	}
+	updated := cloneStation(*station)
+	s.stations[i].Name = updated.Name
+	s.stations[i].Slug = updated.Slug
+	s.stations[i].Aliases = updated.Aliases
+	s.stations[i].Image = updated.Image
+	s.stations[i].Location = updated.Location
+	return nil
+}
+
+func (s *MemoryStore) ReplaceStation(ctx context.Context, station *models.Station) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.stationIndex(station.ID)
+	if i < 0 {
+		return models.ErrNotFound
+	}
+	if s.stationTaken(station, station.ID) {
+		return models.ErrDuplicate
+	}
+	s.stations[i] = cloneStation(*station)
+	return nil
+}
+
+func (s *MemoryStore) DeleteStation(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.stationIndex(id)
+	if i < 0 {
+		return models.ErrNotFound
+	}
+	s.stations = append(s.stations[:i], s.stations[i+1:]...)
+	return nil
+}
+
+func (s *MemoryStore) NearestStations(ctx context.Context, lng, lat, maxDistance float64, limit int) ([]models.StationDistance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]models.StationDistance, 0, len(s.stations))
+	for _, station := range s.stations {
+		if len(station.Location.Coordinates) != 2 {
+			continue
+		}
+		distance := utils.HaversineDistance(lat, lng, station.Location.Coordinates[1], station.Location.Coordinates[0]) * 1000
+		if maxDistance > 0 && distance > maxDistance {
+			continue
+		}
+		results = append(results, models.StationDistance{
+			Station:  cloneStation(station),
+			Distance: distance,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (s *MemoryStore) StationsWithin(ctx context.Context, minLng, minLat, maxLng, maxLat float64) ([]models.Station, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stations := []models.Station{}
+	for _, station := range s.stations {
+		if len(station.Location.Coordinates) != 2 {
+			continue
+		}
+		lng, lat := station.Location.Coordinates[0], station.Location.Coordinates[1]
+		if lng >= minLng && lng <= maxLng && lat >= minLat && lat <= maxLat {
+			stations = append(stations, cloneStation(station))
+		}
+	}
+	return stations, nil
+}
+
+func (s *MemoryStore) ListRoutes(ctx context.Context) ([]models.Route, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	routes := make([]models.Route, 0, len(s.routes))
+	for _, route := range s.routes {
+		routes = append(routes, cloneRoute(route))
+	}
+	return routes, nil
+}
+
+func (s *MemoryStore) GetRoute(ctx context.Context, id primitive.ObjectID) (*models.Route, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.routeIndex(id)
+	if i < 0 {
+		return nil, models.ErrNotFound
+	}
+	route := cloneRoute(s.routes[i])
+	return &route, nil
+}
+
+func (s *MemoryStore) FindRoute(ctx context.Context, from, to string) (*models.Route, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, route := range s.routes {
+		if route.From == from && route.To == to {
+			route = cloneRoute(route)
+			return &route, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (s *MemoryStore) InsertRoute(ctx context.Context, route *models.Route) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.routeTaken(route.From, route.To, primitive.NilObjectID) {
+		return models.ErrDuplicate
+	}
+	if route.ID.IsZero() {
+		route.ID = primitive.NewObjectID()
+	}
+	s.routes = append(s.routes, cloneRoute(*route))
+	return nil
+}
+
+func (s *MemoryStore) UpdateRoute(ctx context.Context, id primitive.ObjectID, route *models.Route) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.routeIndex(id)
+	if i < 0 {
+		return models.ErrNotFound
+	}
+	if s.routeTaken(route.From, route.To, id) {
+		return models.ErrDuplicate
+	}
+	updated := cloneRoute(*route)
+	updated.ID = id
+	s.routes[i] = updated
+	return nil
+}
+
+func (s *MemoryStore) DeleteRoute(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.routeIndex(id)
+	if i < 0 {
+		return models.ErrNotFound
+	}
+	s.routes = append(s.routes[:i], s.routes[i+1:]...)
+	return nil
+}
+
+func (s *MemoryStore) CountRoutesForStation(ctx context.Context, name string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, route := range s.routes {
+		if route.From == name || route.To == name {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func cloneTariff(t models.Tariff) models.Tariff {
+	t.Brackets = append([]models.FareBracket(nil), t.Brackets...)
+	return t
+}
+
+func (s *MemoryStore) ListTariffs(ctx context.Context, vehicleClass string) ([]models.Tariff, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tariffs := []models.Tariff{}
+	for _, tariff := range s.tariffs {
+		if vehicleClass == "" || tariff.VehicleClass == vehicleClass {
+			tariffs = append(tariffs, cloneTariff(tariff))
+		}
+	}
+	sort.SliceStable(tariffs, func(i, j int) bool {
+		return tariffs[i].EffectiveFrom.Before(tariffs[j].EffectiveFrom)
+	})
+	return tariffs, nil
+}
+
+func (s *MemoryStore) TariffAt(ctx context.Context, vehicleClass string, at time.Time) (*models.Tariff, error) {
+	tariffs, err := s.ListTariffs(ctx, vehicleClass)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(tariffs) - 1; i >= 0; i-- {
+		if !tariffs[i].EffectiveFrom.After(at) {
+			return &tariffs[i], nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (s *MemoryStore) InsertTariff(ctx context.Context, tariff *models.Tariff) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tariff.ID.IsZero() {
+		tariff.ID = primitive.NewObjectID()
+	}
+	s.tariffs = append(s.tariffs, cloneTariff(*tariff))
+	return nil
+}
+
+func clonePriceRecord(p models.PriceRecord) models.PriceRecord {
+	p.IntermediateStations = append([]string(nil), p.IntermediateStations...)
+	p.Services = append([]models.Service(nil), p.Services...)
+	if p.ValidUntil != nil {
+		until := *p.ValidUntil
+		p.ValidUntil = &until
+	}
+	return p
+}
+
+func (s *MemoryStore) ListPriceRecords(ctx context.Context, routeID primitive.ObjectID) ([]models.PriceRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := []models.PriceRecord{}
+	for _, record := range s.prices {
+		if routeID.IsZero() || record.RouteID == routeID {
+			records = append(records, clonePriceRecord(record))
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].ValidFrom.Before(records[j].ValidFrom)
+	})
+	return records, nil
+}
+
+func (s *MemoryStore) PriceRecordsAt(ctx context.Context, at time.Time) ([]models.PriceRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := []models.PriceRecord{}
+	for _, record := range s.prices {
+		if record.ValidAt(at) {
+			records = append(records, clonePriceRecord(record))
+		}
+	}
+	return records, nil
+}
+
+func (s *MemoryStore) RecordedRouteIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[primitive.ObjectID]bool)
+	ids := []primitive.ObjectID{}
+	for _, record := range s.prices {
+		if !seen[record.RouteID] {
+			seen[record.RouteID] = true
+			ids = append(ids, record.RouteID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *MemoryStore) InsertPriceRecord(ctx context.Context, record *models.PriceRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	s.prices = append(s.prices, clonePriceRecord(*record))
+	return nil
+}
+
+func (s *MemoryStore) ClosePriceRecords(ctx context.Context, routeID primitive.ObjectID, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.prices {
+		if s.prices[i].RouteID == routeID && s.prices[i].ValidUntil == nil {
+			closed := until
+			s.prices[i].ValidUntil = &closed
+		}
+	}
+	return nil
+}
+
+func cloneSurcharge(sc models.Surcharge) models.Surcharge {
+	sc.Days = append([]string(nil), sc.Days...)
+	sc.Overrides = append([]models.SurchargeOverride(nil), sc.Overrides...)
+	if sc.EffectiveFrom != nil {
+		from := *sc.EffectiveFrom
+		sc.EffectiveFrom = &from
+	}
+	if sc.EffectiveUntil != nil {
+		until := *sc.EffectiveUntil
+		sc.EffectiveUntil = &until
+	}
+	return sc
+}
+
+func (s *MemoryStore) surchargeIndex(id primitive.ObjectID) int {
+	for i, surcharge := range s.surcharges {
+		if surcharge.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *MemoryStore) ListSurcharges(ctx context.Context) ([]models.Surcharge, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	surcharges := make([]models.Surcharge, 0, len(s.surcharges))
+	for _, surcharge := range s.surcharges {
+		surcharges = append(surcharges, cloneSurcharge(surcharge))
+	}
+	return surcharges, nil
+}
+
+func (s *MemoryStore) GetSurcharge(ctx context.Context, id primitive.ObjectID) (*models.Surcharge, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.surchargeIndex(id)
+	if i < 0 {
+		return nil, models.ErrNotFound
+	}
+	surcharge := cloneSurcharge(s.surcharges[i])
+	return &surcharge, nil
+}
+
+// surchargeTaken reports whether a surcharge other than id is named name
+func (s *MemoryStore) surchargeTaken(name string, id primitive.ObjectID) bool {
+	for i := range s.surcharges {
+		if s.surcharges[i].Name == name && s.surcharges[i].ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) InsertSurcharge(ctx context.Context, surcharge *models.Surcharge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.surchargeTaken(surcharge.Name, primitive.NilObjectID) {
+		return models.ErrDuplicate
+	}
+	if surcharge.ID.IsZero() {
+		surcharge.ID = primitive.NewObjectID()
+	}
+	s.surcharges = append(s.surcharges, cloneSurcharge(*surcharge))
+	return nil
+}
+
+func (s *MemoryStore) UpdateSurcharge(ctx context.Context, id primitive.ObjectID, surcharge *models.Surcharge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.surchargeIndex(id)
+	if i < 0 {
+		return models.ErrNotFound
+	}
+	if s.surchargeTaken(surcharge.Name, id) {
+		return models.ErrDuplicate
+	}
+	updated := cloneSurcharge(*surcharge)
+	updated.ID = id
+	s.surcharges[i] = updated
+	return nil
+}
+
+func (s *MemoryStore) DeleteSurcharge(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.surchargeIndex(id)
+	if i < 0 {
+		return models.ErrNotFound
+	}
+	s.surcharges = append(s.surcharges[:i], s.surcharges[i+1:]...)
+	return nil
+}
+
+func (s *MemoryStore) ListHolidays(ctx context.Context) ([]models.Holiday, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	holidays := append([]models.Holiday{}, s.holidays...)
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Date < holidays[j].Date
+	})
+	return holidays, nil
+}
+
+func (s *MemoryStore) InsertHoliday(ctx context.Context, holiday *models.Holiday) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if holiday.ID.IsZero() {
+		holiday.ID = primitive.NewObjectID()
+	}
+	s.holidays = append(s.holidays, *holiday)
+	return nil
+}
+
+func (s *MemoryStore) DeleteHoliday(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, holiday := range s.holidays {
+		if holiday.ID == id {
+			s.holidays = append(s.holidays[:i], s.holidays[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func cloneFareEvent(e models.FareEvent) models.FareEvent {
+	e.SuspendedRoutes = append([]primitive.ObjectID(nil), e.SuspendedRoutes...)
+	return e
+}
+
+func (s *MemoryStore) ListFareEvents(ctx context.Context, from, to string) ([]models.FareEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []models.FareEvent{}
+	for _, event := range s.events {
+		if (from == "" || event.End >= from) && (to == "" || event.Start <= to) {
+			events = append(events, cloneFareEvent(event))
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start < events[j].Start
+	})
+	return events, nil
+}
+
+func (s *MemoryStore) InsertFareEvent(ctx context.Context, event *models.FareEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	s.events = append(s.events, cloneFareEvent(*event))
+	return nil
+}
+
+func (s *MemoryStore) DeleteFareEvent(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, event := range s.events {
+		if event.ID == id {
+			s.events = append(s.events[:i], s.events[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func cloneRoadLeg(l models.RoadLeg) models.RoadLeg {
+	coordinates := make([][]float64, len(l.Coordinates))
+	for i, position := range l.Coordinates {
+		coordinates[i] = append([]float64(nil), position...)
+	}
+	l.Coordinates = coordinates
+	return l
+}
+
+func (s *MemoryStore) GetRoadLeg(ctx context.Context, from, to primitive.ObjectID) (*models.RoadLeg, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, leg := range s.roadLegs {
+		if leg.From == from && leg.To == to {
+			l := cloneRoadLeg(leg)
+			return &l, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (s *MemoryStore) SaveRoadLeg(ctx context.Context, leg *models.RoadLeg) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.roadLegs {
+		if s.roadLegs[i].From == leg.From && s.roadLegs[i].To == leg.To {
+			leg.ID = s.roadLegs[i].ID
+			s.roadLegs[i] = cloneRoadLeg(*leg)
+			return nil
+		}
+	}
+	leg.ID = primitive.NewObjectID()
+	s.roadLegs = append(s.roadLegs, cloneRoadLeg(*leg))
+	return nil
+}
+
+func (s *MemoryStore) DeleteRoadLegs(ctx context.Context, station primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.roadLegs[:0]
+	for _, leg := range s.roadLegs {
+		if leg.From != station && leg.To != station {
+			kept = append(kept, leg)
+		}
+	}
+	s.roadLegs = kept
+	return nil
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"taxi-fare-calculator/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemoryStorePriceRecordsAreCopies(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	record := models.PriceRecord{
+		RouteID:              primitive.NewObjectID(),
+		From:                 "Mexico Station",
+		To:                   "Piassa Station",
+		IntermediateStations: []string{"Sebategna Station"},
+		Services:             []models.Service{{VehicleClass: models.VehicleMinibus, Price: 10 * models.Birr}},
+	}
+	if err := store.InsertPriceRecord(ctx, &record); err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither the inserted record nor a listed one shares slices with the store
+	record.Services[0].Price = 99 * models.Birr
+	record.IntermediateStations[0] = "Arat Kilo Station"
+	listed, err := store.PriceRecordsAt(ctx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed[0].Services[0].Price = 99 * models.Birr
+
+	stored, err := store.ListPriceRecords(ctx, record.RouteID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stored[0].Services[0].Price; got != 10*models.Birr {
+		t.Errorf("stored service price = %s, want 10", got)
+	}
+	if got := stored[0].IntermediateStations[0]; got != "Sebategna Station" {
+		t.Errorf("stored intermediate station = %s, want Sebategna Station", got)
+	}
+}
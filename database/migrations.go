@@ -0,0 +1,300 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrationsCollection records the schema migrations applied to a database
+const migrationsCollection = "schema_migrations"
+
+// ErrDuplicateData is returned when stored documents prevent a unique index from being created
+var ErrDuplicateData = errors.New("stored data has duplicates")
+
+// Migration is a versioned, one-off change to the stored data. Migrations are
+// applied once each, in order of version.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// AppliedMigration is the record kept for a migration once it has run
+type AppliedMigration struct {
+	Version     int       `json:"version" bson:"_id"`
+	Description string    `json:"description" bson:"description"`
+	AppliedAt   time.Time `json:"applied_at" bson:"applied_at"`
+}
+
+// index is an index the stores rely on, created or verified on every migration run
+type index struct {
+	collection string
+	model      mongo.IndexModel
+	// unique fields are checked for duplicates before the index is created,
+	// so the warning names the offending documents
+	unique []string
+}
+
+// indexes lists every index the stores need
+var indexes = []index{
+	{collection: "stations", model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "location", Value: "2dsphere"}},
+		Options: options.Index().SetName("location_2dsphere"),
+	}},
+	{collection: "stations", unique: []string{"name"}, model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetName("name_unique").SetUnique(true),
+	}},
+	{collection: "stations", unique: []string{"slug"}, model: mongo.IndexModel{
+		Keys: bson.D{{Key: "slug", Value: 1}},
+		Options: options.Index().SetName("slug_unique").SetUnique(true).
+			SetPartialFilterExpression(bson.M{"slug": bson.M{"$type": "string"}}),
+	}},
+	{collection: "routes", unique: []string{"from", "to"}, model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "from", Value: 1}, {Key: "to", Value: 1}},
+		Options: options.Index().SetName("from_to_unique").SetUnique(true),
+	}},
+	{collection: "route_prices", model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "route_id", Value: 1}, {Key: "valid_from", Value: 1}},
+		Options: options.Index().SetName("route_id_valid_from"),
+	}},
+	{collection: "surcharges", unique: []string{"name"}, model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetName("name_unique").SetUnique(true),
+	}},
+	{collection: "road_legs", unique: []string{"from", "to"}, model: mongo.IndexModel{
+		Keys:    bson.D{{Key: "from", Value: 1}, {Key: "to", Value: 1}},
+		Options: options.Index().SetName("from_to_unique").SetUnique(true),
+	}},
+}
+
+// migrations lists the schema migrations in order of version. Append new ones
+// with the next version; never renumber or edit one that has been released.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "default the GeoJSON type of station locations",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("stations").UpdateMany(ctx,
+				bson.M{"location.coordinates": bson.M{"$exists": true}, "location.type": bson.M{"$in": bson.A{nil, ""}}},
+				bson.M{"$set": bson.M{"location.type": "Point"}},
+			)
+			return err
+		},
+	},
+	{
+		Version:     2,
+		Description: "trim whitespace around station and route names",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			trim := func(field string) bson.M {
+				return bson.M{"$trim": bson.M{"input": "$" + field}}
+			}
+			if _, err := db.Collection("stations").UpdateMany(ctx,
+				bson.M{"name": bson.M{"$regex": `^\s|\s$`}},
+				mongo.Pipeline{{{Key: "$set", Value: bson.M{"name": trim("name")}}}},
+			); err != nil {
+				return err
+			}
+			_, err := db.Collection("routes").UpdateMany(ctx,
+				bson.M{"$or": bson.A{
+					bson.M{"from": bson.M{"$regex": `^\s|\s$`}},
+					bson.M{"to": bson.M{"$regex": `^\s|\s$`}},
+				}},
+				mongo.Pipeline{{{Key: "$set", Value: bson.M{"from": trim("from"), "to": trim("to")}}}},
+			)
+			return err
+		},
+	},
+	{
+		Version:     3,
+		Description: "give stations a slug derived from their name",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			collection := db.Collection("stations")
+			stations, err := findAll[models.Station](ctx, collection, bson.M{})
+			if err != nil {
+				return err
+			}
+			taken := make(map[string]bool)
+			for _, station := range stations {
+				if station.Slug != "" {
+					taken[station.Slug] = true
+				}
+			}
+			for _, station := range stations {
+				if station.Slug != "" {
+					continue
+				}
+				base := models.StationSlug(station.Name)
+				if base == "" {
+					continue
+				}
+				// Stations that only differ by the " Station" suffix get numbered slugs
+				slug := base
+				for n := 2; taken[slug]; n++ {
+					slug = fmt.Sprintf("%s-%d", base, n)
+				}
+				if slug != base {
+					log.Printf("⚠️ Station %s shares its slug with another station, using %s", station.Name, slug)
+				}
+				taken[slug] = true
+				if _, err := collection.UpdateOne(ctx, bson.M{"_id": station.ID}, bson.M{"$set": bson.M{"slug": slug}}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// Migrate applies the pending migrations in order, then creates the indexes
+// the stores rely on. It is safe to run on every start: applied migrations
+// are skipped and existing indexes are left alone. Returns the migrations
+// applied by this run.
+func Migrate(ctx context.Context, db *mongo.Database) ([]AppliedMigration, error) {
+	pending, err := PendingMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []AppliedMigration
+	for _, migration := range pending {
+		log.Printf("Applying migration %d: %s", migration.Version, migration.Description)
+		if err := migration.Up(ctx, db); err != nil {
+			return applied, fmt.Errorf("error applying migration %d (%s): %v", migration.Version, migration.Description, err)
+		}
+		record := AppliedMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+		}
+		if _, err := db.Collection(migrationsCollection).InsertOne(ctx, record); err != nil {
+			return applied, fmt.Errorf("error recording migration %d: %v", migration.Version, err)
+		}
+		applied = append(applied, record)
+	}
+
+	if err := EnsureIndexes(ctx, db); err != nil {
+		return applied, err
+	}
+	return applied, nil
+}
+
+// AppliedMigrations returns the migrations recorded as applied, in order of version
+func AppliedMigrations(ctx context.Context, db *mongo.Database) ([]AppliedMigration, error) {
+	sortOrder := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	return findAll[AppliedMigration](ctx, db.Collection(migrationsCollection), bson.M{}, sortOrder)
+}
+
+// PendingMigrations returns the migrations not yet applied, in order of version
+func PendingMigrations(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	applied, err := AppliedMigrations(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("error reading applied migrations: %v", err)
+	}
+	done := make(map[int]bool)
+	for _, record := range applied {
+		done[record.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+	return pending, nil
+}
+
+// EnsureIndexes creates the indexes the stores rely on, leaving existing ones
+// alone. A unique index the stored data would violate is skipped with the
+// duplicates logged, so a deployment with duplicates still starts and the
+// index is created by the first run after they are resolved.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, idx := range indexes {
+		collection := db.Collection(idx.collection)
+		if len(idx.unique) > 0 {
+			err := checkDuplicates(ctx, collection, idx.unique)
+			if errors.Is(err, ErrDuplicateData) {
+				log.Printf("⚠️ Skipping index %s on %s: %v", *idx.model.Options.Name, idx.collection, err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := collection.Indexes().CreateOne(ctx, idx.model); err != nil {
+			return fmt.Errorf("error creating index %s on %s: %v", *idx.model.Options.Name, idx.collection, err)
+		}
+	}
+	return nil
+}
+
+// maxDuplicatesShown limits how many duplicate keys a failed index check lists
+const maxDuplicatesShown = 10
+
+// duplicate is a combination of field values shared by several documents
+type duplicate struct {
+	Key   bson.M `bson:"_id"`
+	Count int    `bson:"count"`
+}
+
+// checkDuplicates fails when documents in the collection share the values of fields
+func checkDuplicates(ctx context.Context, collection *mongo.Collection, fields []string) error {
+	cursor, err := collection.Aggregate(ctx, duplicatesPipeline(fields))
+	if err != nil {
+		return fmt.Errorf("error checking %s for duplicate %s: %v", collection.Name(), strings.Join(fields, "/"), err)
+	}
+	defer cursor.Close(ctx)
+
+	var duplicates []duplicate
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+	return duplicatesError(collection.Name(), fields, duplicates)
+}
+
+// duplicatesPipeline groups the documents having all fields by their values
+// and keeps the groups of more than one
+func duplicatesPipeline(fields []string) mongo.Pipeline {
+	key := bson.M{}
+	exists := bson.M{}
+	for _, field := range fields {
+		key[field] = "$" + field
+		exists[field] = bson.M{"$exists": true}
+	}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: exists}},
+		{{Key: "$group", Value: bson.M{"_id": key, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$limit", Value: maxDuplicatesShown}},
+	}
+}
+
+// duplicatesError lists the duplicates found in a collection, or returns nil without any
+func duplicatesError(collection string, fields []string, duplicates []duplicate) error {
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	var listed []string
+	for _, duplicate := range duplicates {
+		var values []string
+		for _, field := range fields {
+			values = append(values, fmt.Sprint(duplicate.Key[field]))
+		}
+		listed = append(listed, fmt.Sprintf("%s (%d times)", strings.Join(values, " -> "), duplicate.Count))
+	}
+	return fmt.Errorf("%w: %s has duplicate %s, resolve them to create the index: %s",
+		ErrDuplicateData, collection, strings.Join(fields, "/"), strings.Join(listed, ", "))
+}
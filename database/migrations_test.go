@@ -0,0 +1,97 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDuplicatesPipeline(t *testing.T) {
+	pipeline := duplicatesPipeline([]string{"from", "to"})
+	if len(pipeline) != 4 {
+		t.Fatalf("pipeline has %d stages, want 4", len(pipeline))
+	}
+
+	match := pipeline[0][0].Value.(bson.M)
+	wantMatch := bson.M{"from": bson.M{"$exists": true}, "to": bson.M{"$exists": true}}
+	if !reflect.DeepEqual(match, wantMatch) {
+		t.Errorf("first stage matches %v, want %v", match, wantMatch)
+	}
+	group := pipeline[1][0].Value.(bson.M)
+	wantKey := bson.M{"from": "$from", "to": "$to"}
+	if !reflect.DeepEqual(group["_id"], wantKey) {
+		t.Errorf("documents are grouped by %v, want %v", group["_id"], wantKey)
+	}
+	if limit := pipeline[3][0].Value; limit != maxDuplicatesShown {
+		t.Errorf("pipeline is limited to %v duplicates, want %d", limit, maxDuplicatesShown)
+	}
+}
+
+func TestDuplicatesError(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     []string
+		duplicates []duplicate
+		want       string
+	}{
+		{
+			name:   "no duplicates",
+			fields: []string{"name"},
+		},
+		{
+			name:   "one field",
+			fields: []string{"name"},
+			duplicates: []duplicate{
+				{Key: bson.M{"name": "Mexico Station"}, Count: 2},
+				{Key: bson.M{"name": "Piassa Station"}, Count: 3},
+			},
+			want: "stored data has duplicates: stations has duplicate name, resolve them to create the index: Mexico Station (2 times), Piassa Station (3 times)",
+		},
+		{
+			name:   "fields listed in index order",
+			fields: []string{"from", "to"},
+			duplicates: []duplicate{
+				{Key: bson.M{"to": "Piassa Station", "from": "Mexico Station"}, Count: 2},
+			},
+			want: "stored data has duplicates: stations has duplicate from/to, resolve them to create the index: Mexico Station -> Piassa Station (2 times)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := duplicatesError("stations", tt.fields, tt.duplicates)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("duplicatesError() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrDuplicateData) {
+				t.Fatalf("duplicatesError() = %v, want ErrDuplicateData", err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("duplicatesError() = %q, want %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueIndexesCheckTheirKeys(t *testing.T) {
+	for _, idx := range indexes {
+		unique := idx.model.Options.Unique != nil && *idx.model.Options.Unique
+		if !unique {
+			if len(idx.unique) > 0 {
+				t.Errorf("index %s on %s is not unique but checks for duplicates", *idx.model.Options.Name, idx.collection)
+			}
+			continue
+		}
+		var keys []string
+		for _, key := range idx.model.Keys.(bson.D) {
+			keys = append(keys, key.Key)
+		}
+		if !reflect.DeepEqual(keys, idx.unique) {
+			t.Errorf("unique index %s on %s checks %v for duplicates, want its keys %v", *idx.model.Options.Name, idx.collection, idx.unique, keys)
+		}
+	}
+}
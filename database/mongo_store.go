@@ -0,0 +1,449 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore implements every store interface of the models package, from
+// StationStore to RoadLegStore, on top of MongoDB
+type MongoStore struct {
+	stations   *mongo.Collection
+	routes     *mongo.Collection
+	tariffs    *mongo.Collection
+	prices     *mongo.Collection
+	surcharges *mongo.Collection
+	holidays   *mongo.Collection
+	events     *mongo.Collection
+	roadLegs   *mongo.Collection
+}
+
+func NewMongoStore(db *mongo.Database) *MongoStore {
+	return &MongoStore{
+		stations:   db.Collection("stations"),
+		routes:     db.Collection("routes"),
+		tariffs:    db.Collection("tariffs"),
+		prices:     db.Collection("route_prices"),
+		surcharges: db.Collection("surcharges"),
+		holidays:   db.Collection("holidays"),
+		events:     db.Collection("fare_events"),
+		roadLegs:   db.Collection("road_legs"),
+	}
+}
+
+func findOne[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts ...*options.FindOneOptions) (*T, error) {
+	var doc T
+	err := collection.FindOne(ctx, filter, opts...).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func findAll[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	docs := []T{}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+func (s *MongoStore) ListStations(ctx context.Context) ([]models.Station, error) {
+	return findAll[models.Station](ctx, s.stations, bson.M{})
+}
+
+func (s *MongoStore) GetStation(ctx context.Context, id primitive.ObjectID) (*models.Station, error) {
+	return findOne[models.Station](ctx, s.stations, bson.M{"_id": id})
+}
+
+func (s *MongoStore) GetStationByName(ctx context.Context, name string) (*models.Station, error) {
+	return findOne[models.Station](ctx, s.stations, bson.M{"name": name})
+}
+
+func (s *MongoStore) InsertStation(ctx context.Context, station *models.Station) error {
+	result, err := s.stations.InsertOne(ctx, station)
+	if err != nil {
+		return writeError(err)
+	}
+	station.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoStore) UpdateStation(ctx context.Context, id primitive.ObjectID, station *models.Station) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":     station.Name,
+			"slug":     station.Slug,
+			"aliases":  station.Aliases,
+			"image":    station.Image,
+			"location": station.Location,
+		},
+	}
+
+	result, err := s.stations.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return writeError(err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) ReplaceStation(ctx context.Context, station *models.Station) error {
+	result, err := s.stations.ReplaceOne(ctx, bson.M{"_id": station.ID}, station)
+	if err != nil {
+		return writeError(err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) DeleteStation(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.stations.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) NearestStations(ctx context.Context, lng, lat, maxDistance float64, limit int) ([]models.StationDistance, error) {
+	geoNear := bson.D{
+		{Key: "near", Value: bson.D{
+			{Key: "type", Value: "Point"},
+			{Key: "coordinates", Value: []float64{lng, lat}},
+		}},
+		{Key: "distanceField", Value: "distance"},
+		{Key: "spherical", Value: true},
+	}
+	if maxDistance > 0 {
+		geoNear = append(geoNear, bson.E{Key: "maxDistance", Value: maxDistance})
+	}
+	pipeline := bson.A{bson.D{{Key: "$geoNear", Value: geoNear}}}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	cursor, err := s.stations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []models.StationDistance{}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *MongoStore) StationsWithin(ctx context.Context, minLng, minLat, maxLng, maxLat float64) ([]models.Station, error) {
+	box := bson.M{
+		"type": "Polygon",
+		"coordinates": bson.A{bson.A{
+			bson.A{minLng, minLat},
+			bson.A{maxLng, minLat},
+			bson.A{maxLng, maxLat},
+			bson.A{minLng, maxLat},
+			bson.A{minLng, minLat},
+		}},
+	}
+	return findAll[models.Station](ctx, s.stations, bson.M{"location": bson.M{"$geoWithin": bson.M{"$geometry": box}}})
+}
+
+func (s *MongoStore) ListRoutes(ctx context.Context) ([]models.Route, error) {
+	return findAll[models.Route](ctx, s.routes, bson.M{})
+}
+
+func (s *MongoStore) GetRoute(ctx context.Context, id primitive.ObjectID) (*models.Route, error) {
+	return findOne[models.Route](ctx, s.routes, bson.M{"_id": id})
+}
+
+func (s *MongoStore) FindRoute(ctx context.Context, from, to string) (*models.Route, error) {
+	return findOne[models.Route](ctx, s.routes, bson.M{"from": from, "to": to})
+}
+
+func (s *MongoStore) InsertRoute(ctx context.Context, route *models.Route) error {
+	result, err := s.routes.InsertOne(ctx, route)
+	if err != nil {
+		return writeError(err)
+	}
+	route.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoStore) UpdateRoute(ctx context.Context, id primitive.ObjectID, route *models.Route) error {
+	update := bson.M{
+		"$set": bson.M{
+			"from":                 route.From,
+			"to":                   route.To,
+			"price":                route.Price,
+			"isDirectRoute":        route.IsDirectRoute,
+			"intermediateStations": route.IntermediateStations,
+			"priceSource":          route.PriceSource,
+			"services":             route.Services,
+		},
+	}
+
+	result, err := s.routes.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return writeError(err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) DeleteRoute(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.routes.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) CountRoutesForStation(ctx context.Context, name string) (int64, error) {
+	return s.routes.CountDocuments(ctx, bson.M{
+		"$or": []bson.M{
+			{"from": name},
+			{"to": name},
+		},
+	})
+}
+
+func (s *MongoStore) ListTariffs(ctx context.Context, vehicleClass string) ([]models.Tariff, error) {
+	filter := bson.M{}
+	if vehicleClass != "" {
+		filter["vehicle_class"] = vehicleClass
+	}
+	sortOrder := options.Find().SetSort(bson.D{{Key: "effective_from", Value: 1}, {Key: "created_at", Value: 1}})
+	return findAll[models.Tariff](ctx, s.tariffs, filter, sortOrder)
+}
+
+func (s *MongoStore) TariffAt(ctx context.Context, vehicleClass string, at time.Time) (*models.Tariff, error) {
+	filter := bson.M{
+		"vehicle_class":  vehicleClass,
+		"effective_from": bson.M{"$lte": at},
+	}
+	latest := options.FindOne().SetSort(bson.D{{Key: "effective_from", Value: -1}, {Key: "created_at", Value: -1}})
+	return findOne[models.Tariff](ctx, s.tariffs, filter, latest)
+}
+
+func (s *MongoStore) InsertTariff(ctx context.Context, tariff *models.Tariff) error {
+	result, err := s.tariffs.InsertOne(ctx, tariff)
+	if err != nil {
+		return err
+	}
+	tariff.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoStore) ListPriceRecords(ctx context.Context, routeID primitive.ObjectID) ([]models.PriceRecord, error) {
+	filter := bson.M{}
+	if !routeID.IsZero() {
+		filter["route_id"] = routeID
+	}
+	sortOrder := options.Find().SetSort(bson.D{{Key: "valid_from", Value: 1}, {Key: "_id", Value: 1}})
+	return findAll[models.PriceRecord](ctx, s.prices, filter, sortOrder)
+}
+
+func (s *MongoStore) PriceRecordsAt(ctx context.Context, at time.Time) ([]models.PriceRecord, error) {
+	filter := bson.M{
+		"valid_from": bson.M{"$lte": at},
+		"$or": bson.A{
+			bson.M{"valid_until": bson.M{"$exists": false}},
+			bson.M{"valid_until": bson.M{"$gt": at}},
+		},
+	}
+	return findAll[models.PriceRecord](ctx, s.prices, filter)
+}
+
+func (s *MongoStore) RecordedRouteIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	values, err := s.prices.Distinct(ctx, "route_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *MongoStore) InsertPriceRecord(ctx context.Context, record *models.PriceRecord) error {
+	result, err := s.prices.InsertOne(ctx, record)
+	if err != nil {
+		return err
+	}
+	record.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoStore) ClosePriceRecords(ctx context.Context, routeID primitive.ObjectID, until time.Time) error {
+	_, err := s.prices.UpdateMany(ctx,
+		bson.M{"route_id": routeID, "valid_until": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"valid_until": until}},
+	)
+	return err
+}
+
+func (s *MongoStore) ListSurcharges(ctx context.Context) ([]models.Surcharge, error) {
+	return findAll[models.Surcharge](ctx, s.surcharges, bson.M{})
+}
+
+func (s *MongoStore) GetSurcharge(ctx context.Context, id primitive.ObjectID) (*models.Surcharge, error) {
+	return findOne[models.Surcharge](ctx, s.surcharges, bson.M{"_id": id})
+}
+
+func (s *MongoStore) InsertSurcharge(ctx context.Context, surcharge *models.Surcharge) error {
+	result, err := s.surcharges.InsertOne(ctx, surcharge)
+	if err != nil {
+		return writeError(err)
+	}
+	surcharge.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoStore) UpdateSurcharge(ctx context.Context, id primitive.ObjectID, surcharge *models.Surcharge) error {
+	replacement := *surcharge
+	replacement.ID = id
+	result, err := s.surcharges.ReplaceOne(ctx, bson.M{"_id": id}, replacement)
+	if err != nil {
+		return writeError(err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) DeleteSurcharge(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.surcharges.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) ListHolidays(ctx context.Context) ([]models.Holiday, error) {
+	byDate := options.Find().SetSort(bson.D{{Key: "date", Value: 1}})
+	return findAll[models.Holiday](ctx, s.holidays, bson.M{}, byDate)
+}
+
+func (s *MongoStore) InsertHoliday(ctx context.Context, holiday *models.Holiday) error {
+	result, err := s.holidays.InsertOne(ctx, holiday)
+	if err != nil {
+		return err
+	}
+	holiday.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoStore) DeleteHoliday(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.holidays.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) ListFareEvents(ctx context.Context, from, to string) ([]models.FareEvent, error) {
+	filter := bson.M{}
+	if from != "" {
+		filter["end"] = bson.M{"$gte": from}
+	}
+	if to != "" {
+		filter["start"] = bson.M{"$lte": to}
+	}
+	byStart := options.Find().SetSort(bson.D{{Key: "start", Value: 1}})
+	return findAll[models.FareEvent](ctx, s.events, filter, byStart)
+}
+
+func (s *MongoStore) InsertFareEvent(ctx context.Context, event *models.FareEvent) error {
+	result, err := s.events.InsertOne(ctx, event)
+	if err != nil {
+		return err
+	}
+	event.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoStore) DeleteFareEvent(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.events.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) GetRoadLeg(ctx context.Context, from, to primitive.ObjectID) (*models.RoadLeg, error) {
+	return findOne[models.RoadLeg](ctx, s.roadLegs, bson.M{"from": from, "to": to})
+}
+
+func (s *MongoStore) SaveRoadLeg(ctx context.Context, leg *models.RoadLeg) error {
+	filter := bson.M{"from": leg.From, "to": leg.To}
+	update := bson.M{
+		"$set": bson.M{
+			"hash":        leg.Hash,
+			"distance":    leg.Distance,
+			"duration":    leg.Duration,
+			"coordinates": leg.Coordinates,
+			"fetched_at":  leg.FetchedAt,
+		},
+	}
+	_, err := s.roadLegs.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *MongoStore) DeleteRoadLegs(ctx context.Context, station primitive.ObjectID) error {
+	_, err := s.roadLegs.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"from": station},
+		bson.M{"to": station},
+	}})
+	return err
+}
+
+// writeError reports writes rejected by a unique index as models.ErrDuplicate
+func writeError(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %v", models.ErrDuplicate, err)
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package dataset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BundleVersion is the schema version written by Export and the newest one Load accepts
+const BundleVersion = 1
+
+// Bundle is the JSON document used to import and export stations and routes
+type Bundle struct {
+	Version    int              `json:"version,omitempty"`
+	ExportedAt *time.Time       `json:"exported_at,omitempty"`
+	Hash       string           `json:"hash,omitempty"`
+	Stations   []models.Station `json:"stations"`
+	Routes     []models.Route   `json:"routes"`
+}
+
+// stationRecord accepts the legacy camelCase "connectedRoutes" key used by older data files
+type stationRecord struct {
+	models.Station
+	LegacyConnectedRoutes []string `json:"connectedRoutes"`
+}
+
+// routeRecord distinguishes a missing "isDirectRoute" from an explicit false
+type routeRecord struct {
+	models.Route
+	IsDirectRoute *bool `json:"isDirectRoute"`
+}
+
+// Load decodes a bundle from JSON
+func Load(r io.Reader) (*Bundle, error) {
+	var raw struct {
+		Version  int             `json:"version"`
+		Hash     string          `json:"hash"`
+		Stations []stationRecord `json:"stations"`
+		Routes   []routeRecord   `json:"routes"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid dataset: %v", err)
+	}
+	if raw.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported dataset version %d (newest supported is %d)", raw.Version, BundleVersion)
+	}
+
+	bundle := &Bundle{Version: raw.Version, Hash: raw.Hash}
+	for _, record := range raw.Stations {
+		station := record.Station
+		station.ID = primitive.NilObjectID
+		if station.ConnectedRoutes == nil {
+			station.ConnectedRoutes = record.LegacyConnectedRoutes
+		}
+		bundle.Stations = append(bundle.Stations, station)
+	}
+	for _, record := range raw.Routes {
+		route := record.Route
+		route.ID = primitive.NilObjectID
+		if record.IsDirectRoute != nil {
+			route.IsDirectRoute = *record.IsDirectRoute
+		} else {
+			// Routes without intermediate stations are direct unless stated otherwise
+			route.IsDirectRoute = len(route.IntermediateStations) == 0
+		}
+		bundle.Routes = append(bundle.Routes, route)
+	}
+	return bundle, nil
+}
+
+// LoadFile decodes a bundle from a JSON file
+func LoadFile(path string) (*Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	bundle, err := Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return bundle, nil
+}
+
+// ComputeHash returns the SHA-256 of the bundle's stations and routes.
+// Document IDs are left out so identical data hashes the same in every environment.
+func (b *Bundle) ComputeHash() string {
+	content := struct {
+		Version  int              `json:"version"`
+		Stations []models.Station `json:"stations"`
+		Routes   []models.Route   `json:"routes"`
+	}{Version: b.Version}
+
+	for _, station := range b.Stations {
+		station.ID = primitive.NilObjectID
+		content.Stations = append(content.Stations, station)
+	}
+	for _, route := range b.Routes {
+		route.ID = primitive.NilObjectID
+		content.Routes = append(content.Routes, route)
+	}
+
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Merge appends the stations and routes of other to the bundle
+func (b *Bundle) Merge(other *Bundle) {
+	b.Stations = append(b.Stations, other.Stations...)
+	b.Routes = append(b.Routes, other.Routes...)
+}
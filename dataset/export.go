@@ -0,0 +1,61 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"taxi-fare-calculator/models"
+	"time"
+)
+
+// snapshotAttempts bounds how often Export retries when stations change while it reads
+const snapshotAttempts = 3
+
+// Export reads every station and route into a versioned, hashed bundle.
+// Stations are read before and after the routes; if they changed in between the
+// read is retried so the bundle reflects a single point in time.
+func Export(ctx context.Context, stationStore models.StationStore, routeStore models.RouteStore) (*Bundle, error) {
+	for attempt := 1; attempt <= snapshotAttempts; attempt++ {
+		stations, err := stationStore.ListStations(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching stations: %v", err)
+		}
+		routes, err := routeStore.ListRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching routes: %v", err)
+		}
+		after, err := stationStore.ListStations(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching stations: %v", err)
+		}
+
+		bundle := newBundle(stations, routes)
+		if bundle.ComputeHash() != newBundle(after, routes).ComputeHash() {
+			continue
+		}
+
+		now := time.Now().UTC()
+		bundle.ExportedAt = &now
+		bundle.Hash = bundle.ComputeHash()
+		return bundle, nil
+	}
+	return nil, fmt.Errorf("stations kept changing during export, gave up after %d attempts", snapshotAttempts)
+}
+
+// newBundle sorts stations by name and routes by from/to so exports diff cleanly
+func newBundle(stations []models.Station, routes []models.Route) *Bundle {
+	sort.SliceStable(stations, func(i, j int) bool {
+		return stations[i].Name < stations[j].Name
+	})
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].From != routes[j].From {
+			return routes[i].From < routes[j].From
+		}
+		return routes[i].To < routes[j].To
+	})
+	return &Bundle{
+		Version:  BundleVersion,
+		Stations: stations,
+		Routes:   routes,
+	}
+}
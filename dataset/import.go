@@ -0,0 +1,261 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/search"
+)
+
+// ErrInvalidBundle is returned when a bundle fails validation; nothing is written in that case
+var ErrInvalidBundle = errors.New("dataset has invalid or conflicting records")
+
+// Report describes the outcome of an import
+type Report struct {
+	DryRun            bool     `json:"dry_run"`
+	StationsCreated   []string `json:"stations_created"`
+	StationsUpdated   []string `json:"stations_updated"`
+	StationsUnchanged int      `json:"stations_unchanged"`
+	RoutesCreated     []string `json:"routes_created"`
+	RoutesUpdated     []string `json:"routes_updated"`
+	RoutesUnchanged   int      `json:"routes_unchanged"`
+	Conflicts         []string `json:"conflicts"`
+	Errors            []string `json:"errors"`
+	Warnings          []string `json:"warnings"`
+}
+
+// sourceImport is the price source of imported routes that do not name one
+const sourceImport = "dataset import"
+
+type ImportOptions struct {
+	DryRun bool
+}
+
+func routeKey(r models.Route) string {
+	return r.From + " -> " + r.To
+}
+
+// Import validates the bundle and upserts its stations (by name) and routes (by from/to).
+// Running the same import twice leaves the store unchanged.
+func Import(ctx context.Context, stationStore models.StationStore, routeStore models.RouteStore, bundle *Bundle, opts ImportOptions) (*Report, error) {
+	report := &Report{DryRun: opts.DryRun}
+
+	if bundle.Hash != "" && bundle.Hash != bundle.ComputeHash() {
+		report.Warnings = append(report.Warnings, "dataset hash does not match its content, the file was modified after export")
+	}
+
+	// Routes may name their stops by any exact name of a station, in the
+	// bundle or stored, and are matched and stored under the station names
+	// like routes written through the API
+	stored, err := stationStore.ListStations(ctx)
+	if err != nil {
+		return report, fmt.Errorf("error fetching stations: %v", err)
+	}
+	known := make([]models.Station, 0, len(bundle.Stations)+len(stored))
+	for _, station := range bundle.Stations {
+		station.Name = strings.TrimSpace(station.Name)
+		known = append(known, station)
+	}
+	index := search.NewIndex(append(known, stored...))
+
+	stations, routes := validate(bundle, index, report)
+	if len(report.Errors) > 0 || len(report.Conflicts) > 0 {
+		return report, ErrInvalidBundle
+	}
+
+	// Upsert stations by name
+	for _, station := range stations {
+		existing, err := stationStore.GetStationByName(ctx, station.Name)
+		if errors.Is(err, models.ErrNotFound) {
+			report.StationsCreated = append(report.StationsCreated, station.Name)
+			if !opts.DryRun {
+				if err := stationStore.InsertStation(ctx, &station); err != nil {
+					return report, fmt.Errorf("error creating station %s: %v", station.Name, err)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("error fetching station %s: %v", station.Name, err)
+		}
+
+		// Keep fields the dataset leaves empty
+		if station.Image == "" {
+			station.Image = existing.Image
+		}
+		if station.ConnectedRoutes == nil {
+			station.ConnectedRoutes = existing.ConnectedRoutes
+		}
+		if station.Aliases == nil {
+			station.Aliases = existing.Aliases
+		}
+		// A slug derived from the name keeps the stored one, which may have been set by hand
+		if station.Slug == models.StationSlug(station.Name) && existing.Slug != "" {
+			station.Slug = existing.Slug
+		}
+		station.ID = existing.ID
+
+		changes := stationChanges(*existing, station)
+		if len(changes) == 0 {
+			report.StationsUnchanged++
+			continue
+		}
+		report.StationsUpdated = append(report.StationsUpdated, fmt.Sprintf("%s (%s)", station.Name, strings.Join(changes, ", ")))
+		if !opts.DryRun {
+			if err := stationStore.ReplaceStation(ctx, &station); err != nil {
+				return report, fmt.Errorf("error updating station %s: %v", station.Name, err)
+			}
+		}
+	}
+
+	// Upsert routes by from/to
+	unknown := make(map[string]bool)
+	for _, route := range routes {
+		for _, name := range append([]string{route.From, route.To}, route.IntermediateStations...) {
+			if _, ok := index.Lookup(name); ok || unknown[name] {
+				continue
+			}
+			report.Warnings = append(report.Warnings, fmt.Sprintf("route %s references unknown station %s", routeKey(route), name))
+			unknown[name] = true
+		}
+
+		if route.PriceSource == "" {
+			route.PriceSource = sourceImport
+		}
+
+		existing, err := routeStore.FindRoute(ctx, route.From, route.To)
+		if errors.Is(err, models.ErrNotFound) {
+			report.RoutesCreated = append(report.RoutesCreated, routeKey(route))
+			if !opts.DryRun {
+				if err := routeStore.InsertRoute(ctx, &route); err != nil {
+					return report, fmt.Errorf("error creating route %s: %v", routeKey(route), err)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("error fetching route %s: %v", routeKey(route), err)
+		}
+
+		changes := routeChanges(*existing, route)
+		if len(changes) == 0 {
+			report.RoutesUnchanged++
+			continue
+		}
+		report.RoutesUpdated = append(report.RoutesUpdated, fmt.Sprintf("%s (%s)", routeKey(route), strings.Join(changes, ", ")))
+		if !opts.DryRun {
+			if err := routeStore.UpdateRoute(ctx, existing.ID, &route); err != nil {
+				return report, fmt.Errorf("error updating route %s: %v", routeKey(route), err)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// validate checks every record and collapses exact duplicates, recording errors and conflicts in the report.
+// Route stops are named by the stations of the index they refer to.
+func validate(bundle *Bundle, index *search.Index, report *Report) ([]models.Station, []models.Route) {
+	var stations []models.Station
+	seenStations := make(map[string]int)
+	seenSlugs := make(map[string]string)
+	for i, station := range bundle.Stations {
+		station.Name = strings.TrimSpace(station.Name)
+		if err := station.Validate(); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("station #%d (%s): %v", i+1, station.Name, err))
+			continue
+		}
+		if j, ok := seenStations[station.Name]; ok {
+			if changes := stationChanges(stations[j], station); len(changes) > 0 {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("station %s is defined more than once (%s)", station.Name, strings.Join(changes, ", ")))
+			}
+			continue
+		}
+		if other, ok := seenSlugs[station.Slug]; ok {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("stations %s and %s have the same slug %s", other, station.Name, station.Slug))
+			continue
+		}
+		seenStations[station.Name] = len(stations)
+		seenSlugs[station.Slug] = station.Name
+		stations = append(stations, station)
+	}
+
+	var routes []models.Route
+	seenRoutes := make(map[string]int)
+	canonical := func(name string) string {
+		name = strings.TrimSpace(name)
+		if station, ok := index.Lookup(name); ok {
+			return station.Name
+		}
+		return name
+	}
+	for i, route := range bundle.Routes {
+		route.From = canonical(route.From)
+		route.To = canonical(route.To)
+		if route.IntermediateStations != nil {
+			stops := make([]string, len(route.IntermediateStations))
+			for j, stop := range route.IntermediateStations {
+				stops[j] = canonical(stop)
+			}
+			route.IntermediateStations = stops
+		}
+		if err := route.Validate(); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("route #%d (%s): %v", i+1, routeKey(route), err))
+			continue
+		}
+		key := routeKey(route)
+		if j, ok := seenRoutes[key]; ok {
+			if changes := routeChanges(routes[j], route); len(changes) > 0 {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("route %s is defined more than once (%s)", key, strings.Join(changes, ", ")))
+			}
+			continue
+		}
+		seenRoutes[key] = len(routes)
+		routes = append(routes, route)
+	}
+
+	return stations, routes
+}
+
+func stationChanges(old, new models.Station) []string {
+	var changes []string
+	if old.Slug != new.Slug {
+		changes = append(changes, fmt.Sprintf("slug %s -> %s", old.Slug, new.Slug))
+	}
+	if !reflect.DeepEqual(old.Aliases, new.Aliases) && (len(old.Aliases) > 0 || len(new.Aliases) > 0) {
+		changes = append(changes, "aliases")
+	}
+	if old.Image != new.Image {
+		changes = append(changes, "image")
+	}
+	if old.Location.Type != new.Location.Type || !reflect.DeepEqual(old.Location.Coordinates, new.Location.Coordinates) {
+		changes = append(changes, fmt.Sprintf("location %v -> %v", old.Location.Coordinates, new.Location.Coordinates))
+	}
+	if len(old.ConnectedRoutes) != len(new.ConnectedRoutes) || (len(old.ConnectedRoutes) > 0 && !reflect.DeepEqual(old.ConnectedRoutes, new.ConnectedRoutes)) {
+		changes = append(changes, "connected routes")
+	}
+	return changes
+}
+
+func routeChanges(old, new models.Route) []string {
+	var changes []string
+	if old.Price != new.Price {
+		changes = append(changes, fmt.Sprintf("price %s -> %s", old.Price, new.Price))
+	}
+	if old.PriceSource != new.PriceSource {
+		changes = append(changes, fmt.Sprintf("price source %q -> %q", old.PriceSource, new.PriceSource))
+	}
+	if old.IsDirectRoute != new.IsDirectRoute {
+		changes = append(changes, fmt.Sprintf("direct %t -> %t", old.IsDirectRoute, new.IsDirectRoute))
+	}
+	if len(old.IntermediateStations) != len(new.IntermediateStations) || (len(old.IntermediateStations) > 0 && !reflect.DeepEqual(old.IntermediateStations, new.IntermediateStations)) {
+		changes = append(changes, "intermediate stations")
+	}
+	if !reflect.DeepEqual(old.AllServices(), new.AllServices()) {
+		changes = append(changes, "services")
+	}
+	return changes
+}
@@ -0,0 +1,155 @@
+package dataset
+
+import (
+	"context"
+	"reflect"
+	"taxi-fare-calculator/database"
+	"taxi-fare-calculator/models"
+	"testing"
+)
+
+func testBundle() *Bundle {
+	return &Bundle{
+		Stations: []models.Station{
+			{Name: "Mexico Station", Location: models.Location{Coordinates: []float64{38.7450, 9.0107}}},
+			{Name: "Piassa Station", Location: models.Location{Coordinates: []float64{38.7520, 9.0330}},
+				Aliases: []models.Alias{{Name: "ፒያሳ", Lang: "am"}}},
+			{Name: "Megenagna Station", Location: models.Location{Coordinates: []float64{38.8010, 9.0200}}},
+		},
+		Routes: []models.Route{
+			{From: "Mexico Station", To: "Piassa Station", Price: 15 * models.Birr, IsDirectRoute: true},
+			{From: "Piassa Station", To: "Megenagna Station", Price: 20 * models.Birr,
+				IntermediateStations: []string{"Mexico Station"}, PriceSource: "survey"},
+		},
+	}
+}
+
+func TestImportIdempotent(t *testing.T) {
+	tests := []struct {
+		name string
+		// change is applied to the bundle before it is imported again
+		change          func(*Bundle)
+		stationsUpdated int
+		routesUpdated   int
+	}{
+		{"same bundle", func(*Bundle) {}, 0, 0},
+		{"new price", func(b *Bundle) { b.Routes[0].Price = 20 * models.Birr }, 0, 1},
+		{"new price source", func(b *Bundle) { b.Routes[1].PriceSource = "circular" }, 0, 1},
+		{"moved station", func(b *Bundle) { b.Stations[0].Location.Coordinates = []float64{38.7451, 9.0108} }, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := database.NewMemoryStore()
+
+			first, err := Import(ctx, store, store, testBundle(), ImportOptions{})
+			if err != nil {
+				t.Fatalf("first import: %v", err)
+			}
+			if len(first.StationsCreated) != 3 || len(first.RoutesCreated) != 2 {
+				t.Fatalf("first import created %v and %v", first.StationsCreated, first.RoutesCreated)
+			}
+
+			bundle := testBundle()
+			tt.change(bundle)
+			second, err := Import(ctx, store, store, bundle, ImportOptions{})
+			if err != nil {
+				t.Fatalf("second import: %v", err)
+			}
+			if len(second.StationsCreated) != 0 || len(second.RoutesCreated) != 0 {
+				t.Errorf("second import created %v and %v", second.StationsCreated, second.RoutesCreated)
+			}
+			if len(second.StationsUpdated) != tt.stationsUpdated || len(second.RoutesUpdated) != tt.routesUpdated {
+				t.Errorf("second import updated %v and %v", second.StationsUpdated, second.RoutesUpdated)
+			}
+			if second.StationsUnchanged != 3-tt.stationsUpdated || second.RoutesUnchanged != 2-tt.routesUpdated {
+				t.Errorf("second import left %d stations and %d routes unchanged", second.StationsUnchanged, second.RoutesUnchanged)
+			}
+
+			// Importing the same bundle again changes nothing
+			third, err := Import(ctx, store, store, bundle, ImportOptions{})
+			if err != nil {
+				t.Fatalf("third import: %v", err)
+			}
+			if third.StationsUnchanged != 3 || third.RoutesUnchanged != 2 {
+				t.Errorf("third import changed %v, %v, %v and %v",
+					third.StationsCreated, third.StationsUpdated, third.RoutesCreated, third.RoutesUpdated)
+			}
+		})
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+
+	report, err := Import(ctx, store, store, testBundle(), ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.StationsCreated) != 3 || len(report.RoutesCreated) != 2 {
+		t.Errorf("dry run reported %v and %v", report.StationsCreated, report.RoutesCreated)
+	}
+	stations, _ := store.ListStations(ctx)
+	routes, _ := store.ListRoutes(ctx)
+	if len(stations) != 0 || len(routes) != 0 {
+		t.Errorf("dry run stored %d stations and %d routes", len(stations), len(routes))
+	}
+}
+
+func TestImportMatchesStationNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   models.Route
+		created int
+		updated int
+		stored  models.Route
+	}{
+		{"name without suffix", models.Route{From: "Mexico", To: "Piassa", Price: 15 * models.Birr, IsDirectRoute: true},
+			0, 0, models.Route{From: "Mexico Station", To: "Piassa Station"}},
+		{"alias with a new price", models.Route{From: "Mexico Station", To: "ፒያሳ", Price: 16 * models.Birr, IsDirectRoute: true},
+			0, 1, models.Route{From: "Mexico Station", To: "Piassa Station"}},
+		{"intermediate stops", models.Route{From: "megenagna", To: "Mexico", Price: 25 * models.Birr, IntermediateStations: []string{" Piassa "}},
+			1, 0, models.Route{From: "Megenagna Station", To: "Mexico Station", IntermediateStations: []string{"Piassa Station"}}},
+		{"unknown stations are kept as given", models.Route{From: "Mexico", To: " Kality ", Price: 30 * models.Birr, IsDirectRoute: true},
+			1, 0, models.Route{From: "Mexico Station", To: "Kality"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := database.NewMemoryStore()
+			if _, err := Import(ctx, store, store, testBundle(), ImportOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			// A later bundle with routes only refers to the stored stations
+			report, err := Import(ctx, store, store, &Bundle{Routes: []models.Route{tt.route}}, ImportOptions{})
+			if err != nil {
+				t.Fatalf("import: %v %v", err, report.Errors)
+			}
+			if len(report.RoutesCreated) != tt.created || len(report.RoutesUpdated) != tt.updated {
+				t.Errorf("created %v and updated %v", report.RoutesCreated, report.RoutesUpdated)
+			}
+			stored, err := store.FindRoute(ctx, tt.stored.From, tt.stored.To)
+			if err != nil {
+				t.Fatalf("route %s -> %s is not stored: %v", tt.stored.From, tt.stored.To, err)
+			}
+			if !reflect.DeepEqual(stored.IntermediateStations, tt.stored.IntermediateStations) {
+				t.Errorf("intermediate stations = %v, want %v", stored.IntermediateStations, tt.stored.IntermediateStations)
+			}
+		})
+	}
+}
+
+func TestImportCollapsesRoutesNamedDifferently(t *testing.T) {
+	bundle := testBundle()
+	bundle.Routes = append(bundle.Routes, models.Route{From: "Mexico", To: "Piassa", Price: 15 * models.Birr, IsDirectRoute: true})
+
+	report, err := Import(context.Background(), database.NewMemoryStore(), database.NewMemoryStore(), bundle, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.RoutesCreated) != 2 {
+		t.Errorf("routes created = %v, want the same route once", report.RoutesCreated)
+	}
+}
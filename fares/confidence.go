@@ -0,0 +1,78 @@
+package fares
+
+import (
+	"math"
+	"taxi-fare-calculator/models"
+	"time"
+)
+
+// sourceConfidence is the confidence in a freshly verified price of each kind of leg
+var sourceConfidence = map[string]float64{
+	models.LegSourceRoute:    0.95,
+	models.LegSourceSplit:    0.8,
+	models.LegSourceEstimate: 0.5,
+}
+
+const (
+	// confidenceHalfLife is the age at which a route price has lost half of
+	// the confidence that staleness can take away
+	confidenceHalfLife = 365 * 24 * time.Hour
+	// unverifiedAge is assumed for route prices recorded before price history was kept
+	unverifiedAge = confidenceHalfLife
+)
+
+// Assess scores every leg from how its price was obtained and how long ago
+// its route was verified, and gives the journey the price-weighted mean of the
+// leg scores. verified maps route IDs to when their price was recorded and
+// ages are measured at the time of travel.
+func Assess(journey *models.Journey, verified map[string]time.Time, at time.Time) {
+	leg := 0
+	for _, ride := range journey.Rides {
+		for stop := 1; stop < len(ride.Stops) && leg < len(journey.Legs); stop++ {
+			l := &journey.Legs[leg]
+			if recorded, ok := verified[ride.RouteID]; ok {
+				l.VerifiedAt = &recorded
+			}
+			l.Confidence = legConfidence(l, at)
+			leg++
+		}
+	}
+
+	var weighted, plain, total float64
+	for _, l := range journey.Legs {
+		weighted += l.Confidence * l.Price.Birr()
+		plain += l.Confidence
+		total += l.Price.Birr()
+	}
+	switch {
+	case total > 0:
+		journey.Confidence = roundScore(weighted / total)
+	case len(journey.Legs) > 0:
+		journey.Confidence = roundScore(plain / float64(len(journey.Legs)))
+	default:
+		journey.Confidence = 0
+	}
+}
+
+// legConfidence fades the confidence of route prices with age towards half
+// of their source confidence. Estimates are as good as the tariff and do not age.
+func legConfidence(leg *models.RouteLeg, at time.Time) float64 {
+	base := sourceConfidence[leg.Source]
+	if leg.Source == models.LegSourceEstimate {
+		return base
+	}
+
+	age := unverifiedAge
+	if leg.VerifiedAt != nil {
+		age = at.Sub(*leg.VerifiedAt)
+		if age < 0 {
+			age = 0
+		}
+	}
+	fade := math.Pow(0.5, float64(age)/float64(confidenceHalfLife))
+	return roundScore(base * (0.5 + 0.5*fade))
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,127 @@
+package fares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrNoTariff is returned when no tariff is in force for a vehicle class
+	ErrNoTariff = errors.New("no tariff in force")
+	// ErrInvalidTariff is returned when publishing a tariff that fails validation
+	ErrInvalidTariff = errors.New("invalid tariff")
+	// ErrOutOfOrder is returned when publishing a tariff that takes effect
+	// before the latest published tariff of its vehicle class
+	ErrOutOfOrder = errors.New("tariff takes effect before the latest published tariff")
+)
+
+// Engine computes distance fares from the tariffs published in the store and
+// applies surcharge rules and fare events. Until a minibus tariff is published the built-in
+// default table applies.
+type Engine struct {
+	tariffs    models.TariffStore
+	surcharges models.SurchargeStore
+	events     models.FareEventStore
+	rounding   models.Money
+	location   *time.Location
+}
+
+// NewEngine creates the fare engine; journey fares are rounded per ride to a
+// multiple of rounding
+func NewEngine(tariffs models.TariffStore, surcharges models.SurchargeStore, events models.FareEventStore, rounding models.Money) *Engine {
+	location, err := time.LoadLocation("Africa/Addis_Ababa")
+	if err != nil {
+		location = time.FixedZone("EAT", 3*60*60)
+	}
+	return &Engine{
+		tariffs:    tariffs,
+		surcharges: surcharges,
+		events:     events,
+		rounding:   rounding,
+		location:   location,
+	}
+}
+
+// TariffAt returns the tariff of a vehicle class in force at the given time
+func (e *Engine) TariffAt(ctx context.Context, vehicleClass string, at time.Time) (*models.Tariff, error) {
+	if vehicleClass == "" {
+		vehicleClass = models.VehicleMinibus
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	tariff, err := e.tariffs.TariffAt(ctx, vehicleClass, at)
+	if errors.Is(err, models.ErrNotFound) {
+		if vehicleClass == models.VehicleMinibus {
+			fallback := models.DefaultTariff()
+			return &fallback, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoTariff, vehicleClass)
+	}
+	return tariff, err
+}
+
+// Tariffs returns the published tariffs of a vehicle class (all classes if
+// empty), oldest first, each with the time it stopped being in force
+func (e *Engine) Tariffs(ctx context.Context, vehicleClass string) ([]models.Tariff, error) {
+	tariffs, err := e.tariffs.ListTariffs(ctx, vehicleClass)
+	if err != nil {
+		return nil, err
+	}
+
+	next := make(map[string]time.Time)
+	for i := len(tariffs) - 1; i >= 0; i-- {
+		if until, ok := next[tariffs[i].VehicleClass]; ok {
+			tariffs[i].ValidUntil = &until
+		}
+		next[tariffs[i].VehicleClass] = tariffs[i].EffectiveFrom
+	}
+	return tariffs, nil
+}
+
+// CalculateFare calculates the fare of a vehicle class for a distance in
+// kilometers using the tariff in force at the given time
+func (e *Engine) CalculateFare(ctx context.Context, vehicleClass string, distance float64, at time.Time) (models.Money, error) {
+	tariff, err := e.TariffAt(ctx, vehicleClass, at)
+	if err != nil {
+		return 0, err
+	}
+	fare, err := tariff.Fare(distance)
+	if err != nil {
+		return 0, fmt.Errorf("%s tariff effective from %s: %w", tariff.VehicleClass, tariff.EffectiveFrom.Format(time.RFC3339), err)
+	}
+	return fare, nil
+}
+
+// Publish validates and stores a new tariff. Tariffs of a vehicle class form
+// a timeline, so a new one cannot take effect before the latest one. The ID
+// and validity of the tariff are set by the store and the timeline, never
+// by the publisher.
+func (e *Engine) Publish(ctx context.Context, tariff *models.Tariff) error {
+	if err := tariff.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTariff, err)
+	}
+	tariff.ID = primitive.NilObjectID
+	tariff.ValidUntil = nil
+	now := time.Now().UTC()
+	if tariff.EffectiveFrom.IsZero() {
+		tariff.EffectiveFrom = now
+	}
+	tariff.CreatedAt = now
+
+	published, err := e.tariffs.ListTariffs(ctx, tariff.VehicleClass)
+	if err != nil {
+		return err
+	}
+	if n := len(published); n > 0 && tariff.EffectiveFrom.Before(published[n-1].EffectiveFrom) {
+		return ErrOutOfOrder
+	}
+
+	return e.tariffs.InsertTariff(ctx, tariff)
+}
@@ -0,0 +1,62 @@
+package fares
+
+import (
+	"context"
+	"taxi-fare-calculator/calendar"
+	"taxi-fare-calculator/models"
+	"time"
+)
+
+// LocalDate returns the Addis Ababa calendar date of a time as YYYY-MM-DD,
+// today for a zero time
+func (e *Engine) LocalDate(at time.Time) string {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return at.In(e.location).Format("2006-01-02")
+}
+
+// EventsAt returns the fare events in force on the day of the given time
+func (e *Engine) EventsAt(ctx context.Context, at time.Time) ([]models.FareEvent, error) {
+	date := e.LocalDate(at)
+	return e.events.ListFareEvents(ctx, date, date)
+}
+
+// IsHoliday reports whether a date (YYYY-MM-DD) is a fixed Ethiopian public
+// holiday or in the stored holiday calendar
+func (e *Engine) IsHoliday(ctx context.Context, date string) (bool, error) {
+	if len(calendar.HolidaysOn(date)) > 0 {
+		return true, nil
+	}
+	holidays, err := e.surcharges.ListHolidays(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, holiday := range holidays {
+		if holiday.Date == date {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ApplyEvents scales the fares of a journey by the multipliers of the fare
+// events in force and lists the events and their notices
+func ApplyEvents(journey *models.Journey, events []models.FareEvent) {
+	multiplier := 1.0
+	for _, event := range events {
+		multiplier *= event.Multiplier
+		journey.Events = append(journey.Events, event.Name)
+		if event.Notice != "" {
+			journey.Notices = append(journey.Notices, event.Notice)
+		}
+	}
+	if multiplier == 1 {
+		return
+	}
+
+	for i := range journey.Legs {
+		journey.Legs[i].Price = journey.Legs[i].Price.Mul(multiplier)
+	}
+	recount(journey)
+}
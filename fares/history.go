@@ -0,0 +1,210 @@
+package fares
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sourceManual is recorded for price changes that do not name a source
+const sourceManual = "manual"
+
+// History keeps a versioned record of route prices so journeys can be priced
+// as of any past moment
+type History struct {
+	routes  models.RouteStore
+	records models.PriceHistoryStore
+}
+
+func NewHistory(routes models.RouteStore, records models.PriceHistoryStore) *History {
+	return &History{routes: routes, records: records}
+}
+
+// Records returns the price versions of a route, oldest first
+func (h *History) Records(ctx context.Context, routeID primitive.ObjectID) ([]models.PriceRecord, error) {
+	return h.records.ListPriceRecords(ctx, routeID)
+}
+
+// Routes returns the routes in force at the given time, the current routes
+// for a zero or future time
+func (h *History) Routes(ctx context.Context, at time.Time) ([]models.Route, error) {
+	if at.IsZero() || !at.Before(time.Now()) {
+		return h.routes.ListRoutes(ctx)
+	}
+	return h.RoutesAt(ctx, at)
+}
+
+// RoutesAt reconstructs the route network as it was at the given time.
+// Routes without any recorded history are assumed to have always existed.
+func (h *History) RoutesAt(ctx context.Context, at time.Time) ([]models.Route, error) {
+	records, err := h.records.PriceRecordsAt(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := h.records.RecordedRouteIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recorded := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		recorded[id] = true
+	}
+
+	var routes []models.Route
+	for _, record := range records {
+		routes = append(routes, record.Route())
+	}
+
+	current, err := h.routes.ListRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range current {
+		if !recorded[route.ID] {
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+// VerifiedAt returns, for each of the given routes with a known history, when
+// the price version in force at the given time was recorded
+func (h *History) VerifiedAt(ctx context.Context, routeIDs []string, at time.Time) (map[string]time.Time, error) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	verified := make(map[string]time.Time)
+	for _, hex := range routeIDs {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			continue
+		}
+		if _, ok := verified[hex]; ok {
+			continue
+		}
+		records, err := h.records.ListPriceRecords(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if record.ValidAt(at) && !record.ValidFrom.IsZero() {
+				verified[hex] = record.ValidFrom
+			}
+		}
+	}
+	return verified, nil
+}
+
+// record closes the open version of a route and opens one for its new state.
+// For routes written before history was kept, the previous state is recorded
+// as valid since an unknown time.
+func (h *History) record(ctx context.Context, old, new *models.Route, at time.Time) error {
+	if old != nil {
+		previous, err := h.records.ListPriceRecords(ctx, old.ID)
+		if err != nil {
+			return err
+		}
+		if len(previous) == 0 {
+			record := newPriceRecord(old, time.Time{})
+			record.ValidUntil = &at
+			if err := h.records.InsertPriceRecord(ctx, &record); err != nil {
+				return err
+			}
+		} else if err := h.records.ClosePriceRecords(ctx, old.ID, at); err != nil {
+			return err
+		}
+	}
+
+	if new != nil {
+		record := newPriceRecord(new, at)
+		return h.records.InsertPriceRecord(ctx, &record)
+	}
+	return nil
+}
+
+func newPriceRecord(route *models.Route, from time.Time) models.PriceRecord {
+	source := route.PriceSource
+	if source == "" {
+		source = sourceManual
+	}
+	return models.PriceRecord{
+		RouteID:              route.ID,
+		From:                 route.From,
+		To:                   route.To,
+		Price:                route.Price,
+		IsDirectRoute:        route.IsDirectRoute,
+		IntermediateStations: append([]string(nil), route.IntermediateStations...),
+		Services:             append([]models.Service(nil), route.Services...),
+		Source:               source,
+		ValidFrom:            from,
+	}
+}
+
+// versionChanged reports whether an update changes what a journey over the route costs
+func versionChanged(old, new models.Route) bool {
+	return old.Price != new.Price ||
+		old.From != new.From ||
+		old.To != new.To ||
+		old.IsDirectRoute != new.IsDirectRoute ||
+		!reflect.DeepEqual(old.IntermediateStations, new.IntermediateStations) ||
+		!reflect.DeepEqual(old.AllServices(), new.AllServices())
+}
+
+// WrapRoutes returns a route store that records a price version on every route write
+func (h *History) WrapRoutes(store models.RouteStore) models.RouteStore {
+	return &routeStore{RouteStore: store, history: h}
+}
+
+type routeStore struct {
+	models.RouteStore
+	history *History
+}
+
+func (s *routeStore) InsertRoute(ctx context.Context, route *models.Route) error {
+	if err := s.RouteStore.InsertRoute(ctx, route); err != nil {
+		return err
+	}
+	s.recordChange(ctx, nil, route)
+	return nil
+}
+
+func (s *routeStore) UpdateRoute(ctx context.Context, id primitive.ObjectID, route *models.Route) error {
+	old, err := s.RouteStore.GetRoute(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.RouteStore.UpdateRoute(ctx, id, route); err != nil {
+		return err
+	}
+
+	updated := *route
+	updated.ID = id
+	if versionChanged(*old, updated) {
+		s.recordChange(ctx, old, &updated)
+	}
+	return nil
+}
+
+func (s *routeStore) DeleteRoute(ctx context.Context, id primitive.ObjectID) error {
+	old, err := s.RouteStore.GetRoute(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.RouteStore.DeleteRoute(ctx, id); err != nil {
+		return err
+	}
+	s.recordChange(ctx, old, nil)
+	return nil
+}
+
+// recordChange records a route write that already succeeded, so a failure
+// to record it is logged rather than reported to the writer
+func (s *routeStore) recordChange(ctx context.Context, old, new *models.Route) {
+	if err := s.history.record(ctx, old, new, time.Now().UTC()); err != nil {
+		log.Printf("⚠️ Failed to record route price history: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package fares
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"taxi-fare-calculator/database"
+	"taxi-fare-calculator/models"
+	"testing"
+	"time"
+)
+
+func TestRoutesAt(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	history := NewHistory(store, store)
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Mexico -> Piassa opens in January at 10 Birr and costs 15 Birr from February
+	priced := models.Route{From: "Mexico Station", To: "Piassa Station", Price: 10 * models.Birr, IsDirectRoute: true}
+	if err := store.InsertRoute(ctx, &priced); err != nil {
+		t.Fatal(err)
+	}
+	if err := history.record(ctx, nil, &priced, day(time.January, 1)); err != nil {
+		t.Fatal(err)
+	}
+	raised := priced
+	raised.Price = 15 * models.Birr
+	if err := store.UpdateRoute(ctx, priced.ID, &raised); err != nil {
+		t.Fatal(err)
+	}
+	if err := history.record(ctx, &priced, &raised, day(time.February, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bole -> Megenagna predates the history and closes in March
+	closed := models.Route{From: "Bole Station", To: "Megenagna Station", Price: 20 * models.Birr, IsDirectRoute: true}
+	if err := store.InsertRoute(ctx, &closed); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteRoute(ctx, closed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := history.record(ctx, &closed, nil, day(time.March, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Arat Kilo -> Sidist Kilo was never written through the history
+	unrecorded := models.Route{From: "Arat Kilo Station", To: "Sidist Kilo Station", Price: 5 * models.Birr, IsDirectRoute: true}
+	if err := store.InsertRoute(ctx, &unrecorded); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []string
+	}{
+		{"before the route opened", day(time.December, 1).AddDate(-1, 0, 0), []string{
+			"Arat Kilo Station 5", "Bole Station 20",
+		}},
+		{"first price", day(time.January, 15), []string{
+			"Arat Kilo Station 5", "Bole Station 20", "Mexico Station 10",
+		}},
+		{"price change takes effect on its day", day(time.February, 1), []string{
+			"Arat Kilo Station 5", "Bole Station 20", "Mexico Station 15",
+		}},
+		{"after the route closed", day(time.March, 15), []string{
+			"Arat Kilo Station 5", "Mexico Station 15",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := history.RoutesAt(ctx, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, route := range routes {
+				got = append(got, route.From+" "+route.Price.String())
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RoutesAt(%s) = %v, want %v", tt.at.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package fares
+
+import "taxi-fare-calculator/models"
+
+// Round rounds the fare of every ride to the engine's rounding step, the
+// amounts drivers actually charge, and shares each ride's fare among its legs
+// so legs add up to rides and rides to the total exactly
+func (e *Engine) Round(journey *models.Journey) {
+	leg := 0
+	for _, ride := range journey.Rides {
+		n := len(ride.Stops) - 1
+		if n < 1 || leg+n > len(journey.Legs) {
+			break
+		}
+		legs := journey.Legs[leg : leg+n]
+		weights := make([]models.Money, n)
+		var fare models.Money
+		for i := range legs {
+			weights[i] = legs[i].Price
+			fare += legs[i].Price
+		}
+		for i, price := range fare.Round(e.rounding).Allocate(weights) {
+			legs[i].Price = price
+		}
+		leg += n
+	}
+	recount(journey)
+}
+
+// recount sets ride prices to the sum of their legs and the total to the sum of the rides
+func recount(journey *models.Journey) {
+	leg := 0
+	journey.TotalPrice = 0
+	for i := range journey.Rides {
+		ride := &journey.Rides[i]
+		ride.Price = 0
+		for stop := 1; stop < len(ride.Stops) && leg < len(journey.Legs); stop++ {
+			ride.Price += journey.Legs[leg].Price
+			leg++
+		}
+		journey.TotalPrice += ride.Price
+	}
+}
@@ -0,0 +1,149 @@
+package fares
+
+import (
+	"context"
+	"errors"
+	"taxi-fare-calculator/calendar"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrDuplicateSurcharge is returned when a surcharge rule name is already taken
+var ErrDuplicateSurcharge = errors.New("surcharge name already exists")
+
+// Surcharges returns the stored surcharge rules
+func (e *Engine) Surcharges(ctx context.Context) ([]models.Surcharge, error) {
+	return e.surcharges.ListSurcharges(ctx)
+}
+
+// SeedSurcharges stores the default surcharge rules when none are stored,
+// so night fares keep their surcharge on a new database. Rules removed
+// later are not brought back while any other rule is stored.
+func (e *Engine) SeedSurcharges(ctx context.Context) error {
+	stored, err := e.surcharges.ListSurcharges(ctx)
+	if err != nil || len(stored) > 0 {
+		return err
+	}
+	for _, rule := range models.DefaultSurcharges() {
+		// Another instance starting at the same time may have stored it
+		err := e.surcharges.InsertSurcharge(ctx, &rule)
+		if err != nil && !errors.Is(err, models.ErrDuplicate) {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddSurcharge validates and stores a surcharge rule
+func (e *Engine) AddSurcharge(ctx context.Context, rule *models.Surcharge) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	return surchargeWriteError(e.surcharges.InsertSurcharge(ctx, rule))
+}
+
+// UpdateSurcharge validates and replaces a stored surcharge rule
+func (e *Engine) UpdateSurcharge(ctx context.Context, id primitive.ObjectID, rule *models.Surcharge) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	return surchargeWriteError(e.surcharges.UpdateSurcharge(ctx, id, rule))
+}
+
+// surchargeWriteError reports a name taken by another rule as ErrDuplicateSurcharge
+func surchargeWriteError(err error) error {
+	if errors.Is(err, models.ErrDuplicate) {
+		return ErrDuplicateSurcharge
+	}
+	return err
+}
+
+// ApplySurcharges applies the surcharge rules in force at the time of travel
+// to a journey and lists the names of the rules applied. Multipliers scale
+// every leg of a ride and add-ons are charged once per ride, on its first leg.
+func (e *Engine) ApplySurcharges(ctx context.Context, journey *models.Journey, at time.Time) error {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	local := at.In(e.location)
+
+	stored, err := e.Surcharges(ctx)
+	if err != nil {
+		return err
+	}
+	var rules []models.Surcharge
+	for _, rule := range stored {
+		if rule.InForce(at) {
+			rules = append(rules, rule)
+		}
+	}
+	isHoliday, err := e.holidayCheck(ctx, rules)
+	if err != nil {
+		return err
+	}
+
+	var applied []models.Surcharge
+	for _, rule := range rules {
+		if rule.Applies(local, isHoliday) {
+			applied = append(applied, rule)
+		}
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	leg := 0
+	for _, ride := range journey.Rides {
+		multiplier, addOn := 1.0, models.Money(0)
+		for _, rule := range applied {
+			m, a := rule.Effect(ride.RouteID)
+			multiplier *= m
+			addOn += a
+		}
+
+		for stop := 1; stop < len(ride.Stops) && leg < len(journey.Legs); stop++ {
+			price := journey.Legs[leg].Price.Mul(multiplier)
+			if stop == 1 {
+				price += addOn
+			}
+			journey.Legs[leg].Price = price
+			leg++
+		}
+	}
+	recount(journey)
+
+	for _, rule := range applied {
+		journey.Surcharges = append(journey.Surcharges, rule.Name)
+		if rule.Name == models.SurchargeNight {
+			journey.IsNight = true
+		}
+	}
+	return nil
+}
+
+// holidayCheck loads the holiday calendar if any rule depends on it
+func (e *Engine) holidayCheck(ctx context.Context, rules []models.Surcharge) (func(date string) bool, error) {
+	needed := false
+	for _, rule := range rules {
+		if rule.Holidays != models.HolidaysAny && !rule.Disabled {
+			needed = true
+		}
+	}
+	if !needed {
+		return func(string) bool { return false }, nil
+	}
+
+	holidays, err := e.surcharges.ListHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dates := make(map[string]bool, len(holidays))
+	for _, holiday := range holidays {
+		dates[holiday.Date] = true
+	}
+	return func(date string) bool {
+		return dates[date] || len(calendar.HolidaysOn(date)) > 0
+	}, nil
+}
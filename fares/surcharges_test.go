@@ -0,0 +1,89 @@
+package fares
+
+import (
+	"context"
+	"errors"
+	"taxi-fare-calculator/database"
+	"taxi-fare-calculator/models"
+	"testing"
+)
+
+func TestSeedSurcharges(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored []models.Surcharge
+		want   []string
+	}{
+		{"empty store gets the defaults", nil, []string{models.SurchargeNight}},
+		{"stored rules are kept", []models.Surcharge{{Name: "holiday", Holidays: models.HolidaysOnly, Multiplier: 1.2}}, []string{"holiday"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := database.NewMemoryStore()
+			for i := range tt.stored {
+				if err := store.InsertSurcharge(ctx, &tt.stored[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			engine := NewEngine(store, store, store, 0)
+
+			// Seeding on every start stores the defaults once
+			for i := 0; i < 2; i++ {
+				if err := engine.SeedSurcharges(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rules, err := engine.Surcharges(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, rule := range rules {
+				names = append(names, rule.Name)
+			}
+			if len(names) != len(tt.want) || names[0] != tt.want[0] {
+				t.Errorf("rules = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSurchargeNamesAreUnique(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	engine := NewEngine(store, store, store, 0)
+	night := &models.Surcharge{Name: models.SurchargeNight, Start: "18:30", End: "22:30", Multiplier: 1.4}
+	holiday := &models.Surcharge{Name: "holiday", Holidays: models.HolidaysOnly, Multiplier: 1.2}
+	for _, rule := range []*models.Surcharge{night, holiday} {
+		if err := engine.AddSurcharge(ctx, rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		write func() error
+		want  error
+	}{
+		{"add a taken name", func() error {
+			return engine.AddSurcharge(ctx, &models.Surcharge{Name: " night ", Multiplier: 2})
+		}, ErrDuplicateSurcharge},
+		{"rename to a taken name", func() error {
+			return engine.UpdateSurcharge(ctx, holiday.ID, &models.Surcharge{Name: models.SurchargeNight, Multiplier: 1.2})
+		}, ErrDuplicateSurcharge},
+		{"update keeping the name", func() error {
+			return engine.UpdateSurcharge(ctx, night.ID, &models.Surcharge{Name: models.SurchargeNight, Start: "19:00", End: "23:00", Multiplier: 1.5})
+		}, nil},
+		{"add a new name", func() error {
+			return engine.AddSurcharge(ctx, &models.Surcharge{Name: "late night", Start: "22:30", End: "05:00", Multiplier: 1.6})
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.write(); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/utils"
+)
+
+// Ways of ranking alternative journeys
+const (
+	SortPrice     = "price"
+	SortTransfers = "transfers"
+	SortDistance  = "distance"
+)
+
+// transferWeight makes one transfer outweigh any realistic difference in price
+const transferWeight = 1e6
+
+// Journey is one way of travelling between two stations
+type Journey struct {
+	Route      []string          `json:"route"`
+	Rides      []models.Ride     `json:"rides"`
+	Legs       []models.RouteLeg `json:"legs"`
+	TotalPrice models.Money      `json:"totalPrice"`
+	Transfers  int               `json:"transfers"`
+	DistanceKm float64           `json:"distanceKm"`
+}
+
+// ValidSort reports whether s is a supported ranking
+func ValidSort(s string) bool {
+	return s == SortPrice || s == SortTransfers || s == SortDistance
+}
+
+// Alternatives returns up to k journeys between two stations ranked by
+// sortBy, using Yen's algorithm over the route graph. Journeys never pass
+// through the same station twice.
+func (g *Graph) Alternatives(from, to string, k int, sortBy string) []Journey {
+	weight := weightFunc(g.costWeight)
+	switch sortBy {
+	case SortDistance:
+		weight = g.distanceWeight
+	case SortTransfers:
+		weight = g.transferWeight
+	}
+
+	var journeys []Journey
+	for _, path := range g.kShortestPaths(from, to, k, weight) {
+		journeys = append(journeys, g.journey(path))
+	}
+
+	// Break ties between equally ranked journeys by price
+	sort.SliceStable(journeys, func(i, j int) bool {
+		a, b := journeys[i], journeys[j]
+		switch sortBy {
+		case SortTransfers:
+			if a.Transfers != b.Transfers {
+				return a.Transfers < b.Transfers
+			}
+		case SortDistance:
+			if a.DistanceKm != b.DistanceKm {
+				return a.DistanceKm < b.DistanceKm
+			}
+		}
+		return a.TotalPrice < b.TotalPrice
+	})
+	return journeys
+}
+
+func (g *Graph) distanceWeight(from string, e edge) float64 {
+	if e.kind != rideEdge {
+		return 0
+	}
+	a, _ := parseNode(from)
+	b, _ := parseNode(e.to)
+	return g.distance(a, b)
+}
+
+func (g *Graph) transferWeight(from string, e edge) float64 {
+	if e.kind == boardEdge {
+		return transferWeight
+	}
+	return g.costWeight(from, e)
+}
+
+// kShortestPaths implements Yen's algorithm over the node graph
+func (g *Graph) kShortestPaths(from, to string, k int, weight weightFunc) [][]string {
+	first, _ := g.dijkstra(from, to, weight, nil, nil)
+	if first == nil || k <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		path []string
+		cost float64
+	}
+	found := [][]string{first}
+	seen := map[string]bool{pathKey(first): true}
+	var candidates []candidate
+
+	for len(found) < k {
+		last := found[len(found)-1]
+		for i := 0; i < len(last)-1; i++ {
+			spur := last[i]
+			root := last[:i+1]
+			spurStation, _ := parseNode(spur)
+
+			// Block the next hop of every accepted path sharing this root
+			blockedEdges := make(map[[2]string]bool)
+			for _, path := range found {
+				if len(path) > i+1 && equalPrefix(path, root) {
+					blockedEdges[[2]string{path[i], path[i+1]}] = true
+				}
+			}
+			// Keep the spur path away from the root and the stations on it
+			blockedNodes := make(map[string]bool)
+			rootStations := make(map[string]bool)
+			for _, node := range root[:i] {
+				blockedNodes[node] = true
+				if station, _ := parseNode(node); station != spurStation {
+					rootStations[station] = true
+				}
+			}
+			for node := range g.edges {
+				if station, _ := parseNode(node); rootStations[station] {
+					blockedNodes[node] = true
+				}
+			}
+
+			spurPath, _ := g.dijkstra(spur, to, weight, blockedNodes, blockedEdges)
+			if spurPath == nil {
+				continue
+			}
+
+			path := append(append([]string{}, root[:i]...), spurPath...)
+			key := pathKey(path)
+			if seen[key] || !stationLoopless(path) {
+				continue
+			}
+			seen[key] = true
+			candidates = append(candidates, candidate{path, pathCost(g, path, weight)})
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].cost < candidates[b].cost
+		})
+		found = append(found, candidates[0].path)
+		candidates = candidates[1:]
+	}
+	return found
+}
+
+// journey turns a node path into rides, legs, price, transfers and distance
+func (g *Graph) journey(path []string) Journey {
+	j := Journey{
+		Route: []string{},
+		Rides: []models.Ride{},
+		Legs:  []models.RouteLeg{},
+	}
+
+	var ride *models.Ride
+	for i, node := range path {
+		station, service := parseNode(node)
+		if len(j.Route) == 0 || j.Route[len(j.Route)-1] != station {
+			j.Route = append(j.Route, station)
+		}
+		if i == 0 {
+			continue
+		}
+
+		e := g.findEdge(path[i-1], node)
+		switch e.kind {
+		case boardEdge:
+			routeID, vehicleClass := parseServiceKey(service)
+			j.Rides = append(j.Rides, models.Ride{
+				RouteID:          routeID,
+				VehicleClass:     vehicleClass,
+				FrequencyMinutes: g.services[service].FrequencyMinutes,
+				From:             station,
+				Stops:            []string{station},
+			})
+			ride = &j.Rides[len(j.Rides)-1]
+		case rideEdge:
+			prev, _ := parseNode(path[i-1])
+			ride.Stops = append(ride.Stops, station)
+			ride.Price += e.price
+			ride.To = station
+			j.Legs = append(j.Legs, models.RouteLeg{From: prev, To: station, Price: e.price, VehicleClass: ride.VehicleClass, Source: e.source})
+			j.TotalPrice += e.price
+			j.DistanceKm += g.distance(prev, station)
+		}
+	}
+
+	// Drop boardings that never left the station
+	rides := j.Rides[:0]
+	for _, r := range j.Rides {
+		if len(r.Stops) > 1 {
+			rides = append(rides, r)
+		}
+	}
+	j.Rides = rides
+	if len(j.Rides) > 1 {
+		j.Transfers = len(j.Rides) - 1
+	}
+	return j
+}
+
+// distance is the straight-line distance in kilometers between two stations, 0 if unknown
+func (g *Graph) distance(from, to string) float64 {
+	a, okA := g.stations[from]
+	b, okB := g.stations[to]
+	if !okA || !okB || len(a.Location.Coordinates) != 2 || len(b.Location.Coordinates) != 2 {
+		return 0
+	}
+	return utils.HaversineDistance(
+		a.Location.Coordinates[1], a.Location.Coordinates[0],
+		b.Location.Coordinates[1], b.Location.Coordinates[0],
+	)
+}
+
+func pathCost(g *Graph, path []string, weight weightFunc) float64 {
+	var total float64
+	for i := 1; i < len(path); i++ {
+		total += weight(path[i-1], g.findEdge(path[i-1], path[i]))
+	}
+	return total
+}
+
+// stationLoopless reports whether a node path leaves every station at most once
+func stationLoopless(path []string) bool {
+	visited := make(map[string]bool)
+	prev := ""
+	for _, node := range path {
+		station, _ := parseNode(node)
+		if station == prev {
+			continue
+		}
+		if visited[station] {
+			return false
+		}
+		visited[station] = true
+		prev = station
+	}
+	return true
+}
+
+func pathKey(path []string) string {
+	return strings.Join(path, "\x01")
+}
+
+func equalPrefix(path, prefix []string) bool {
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
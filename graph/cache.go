@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cache keeps the route graph in memory and rebuilds it after station or route
+// writes. maxAge bounds staleness from writes made by other processes.
+type Cache struct {
+	stations models.StationStore
+	routes   models.RouteStore
+	maxAge   time.Duration
+	options  Options
+
+	mu           sync.Mutex // serializes rebuilds
+	graph        *Graph
+	builtAt      time.Time
+	builtVersion uint64
+
+	versionMu sync.Mutex
+	version   uint64
+}
+
+func NewCache(stations models.StationStore, routes models.RouteStore, maxAge time.Duration, options Options) *Cache {
+	return &Cache{
+		stations: stations,
+		routes:   routes,
+		maxAge:   maxAge,
+		options:  options,
+	}
+}
+
+// Invalidate marks the cached graph stale; the next Get rebuilds it
+func (c *Cache) Invalidate() {
+	c.versionMu.Lock()
+	c.version++
+	c.versionMu.Unlock()
+}
+
+func (c *Cache) currentVersion() uint64 {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	return c.version
+}
+
+// Get returns the current graph, rebuilding it if it is stale
+func (c *Cache) Get(ctx context.Context) (*Graph, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	version := c.currentVersion()
+	fresh := c.maxAge <= 0 || time.Since(c.builtAt) < c.maxAge
+	if c.graph != nil && c.builtVersion == version && fresh {
+		return c.graph, nil
+	}
+
+	stations, err := c.stations.ListStations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := c.routes.ListRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.graph = Build(stations, routes, c.options)
+	c.builtAt = time.Now()
+	c.builtVersion = version
+	return c.graph, nil
+}
+
+// BuildWith builds an uncached graph of the current stations over the given
+// routes, such as the network as it was at a past time
+func (c *Cache) BuildWith(ctx context.Context, routes []models.Route) (*Graph, error) {
+	stations, err := c.stations.ListStations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Build(stations, routes, c.options), nil
+}
+
+// WrapStations returns a station store that invalidates the cache on every write
+func (c *Cache) WrapStations(store models.StationStore) models.StationStore {
+	return &stationStore{StationStore: store, cache: c}
+}
+
+// WrapRoutes returns a route store that invalidates the cache on every write
+func (c *Cache) WrapRoutes(store models.RouteStore) models.RouteStore {
+	return &routeStore{RouteStore: store, cache: c}
+}
+
+type stationStore struct {
+	models.StationStore
+	cache *Cache
+}
+
+func (s *stationStore) InsertStation(ctx context.Context, station *models.Station) error {
+	defer s.cache.Invalidate()
+	return s.StationStore.InsertStation(ctx, station)
+}
+
+func (s *stationStore) UpdateStation(ctx context.Context, id primitive.ObjectID, station *models.Station) error {
+	defer s.cache.Invalidate()
+	return s.StationStore.UpdateStation(ctx, id, station)
+}
+
+func (s *stationStore) ReplaceStation(ctx context.Context, station *models.Station) error {
+	defer s.cache.Invalidate()
+	return s.StationStore.ReplaceStation(ctx, station)
+}
+
+func (s *stationStore) DeleteStation(ctx context.Context, id primitive.ObjectID) error {
+	defer s.cache.Invalidate()
+	return s.StationStore.DeleteStation(ctx, id)
+}
+
+type routeStore struct {
+	models.RouteStore
+	cache *Cache
+}
+
+func (s *routeStore) InsertRoute(ctx context.Context, route *models.Route) error {
+	defer s.cache.Invalidate()
+	return s.RouteStore.InsertRoute(ctx, route)
+}
+
+func (s *routeStore) UpdateRoute(ctx context.Context, id primitive.ObjectID, route *models.Route) error {
+	defer s.cache.Invalidate()
+	return s.RouteStore.UpdateRoute(ctx, id, route)
+}
+
+func (s *routeStore) DeleteRoute(ctx context.Context, id primitive.ObjectID) error {
+	defer s.cache.Invalidate()
+	return s.RouteStore.DeleteRoute(ctx, id)
+}
@@ -0,0 +1,395 @@
+package graph
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+	"sync"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/search"
+)
+
+// Options tune how journeys are searched
+type Options struct {
+	// TransferPenalty is the extra cost, in Birr, of changing vehicles. It
+	// steers the search towards fewer transfers but is not charged to the rider.
+	TransferPenalty float64
+}
+
+// boardEpsilon keeps re-boarding the same vehicle from ever tying with staying on it
+const boardEpsilon = 1e-6
+
+type edgeKind int
+
+const (
+	rideEdge   edgeKind = iota // stay on the vehicle to the next stop
+	boardEdge                  // get on a vehicle at a station
+	alightEdge                 // get off a vehicle at a station
+)
+
+type edge struct {
+	to           string
+	kind         edgeKind
+	price        models.Money
+	source       string // how the price of a ride edge was obtained
+	vehicleClass string // set on board edges
+}
+
+// Graph is the station network built from the stored routes.
+//
+// Every station has a node where riders stand, and every service (a vehicle
+// class running along a route) has an on-board node at each station it serves.
+// Ride edges link on-board nodes of the same service, so staying on a vehicle
+// is distinct from transferring, which means alighting to the station node and
+// boarding another service there. Routes are rideable in both directions
+// unless the reverse direction is stored as a route of its own.
+type Graph struct {
+	edges    map[string][]edge
+	stations map[string]models.Station
+	services map[string]models.Service
+	names    *search.Index
+	options  Options
+
+	viewsMu sync.Mutex
+	views   map[string]*Graph // graphs restricted to sets of vehicle classes
+}
+
+// Build creates the graph from stations and routes. Each hop of a non-direct
+// route costs an even share, to the santim, of the fare of the vehicle class taken.
+// Routes may name a station by any of its exact names, such as without the
+// " Station" suffix or by an alias; the station node uses its stored name.
+func Build(stations []models.Station, routes []models.Route, options Options) *Graph {
+	g := &Graph{
+		edges:    make(map[string][]edge),
+		stations: make(map[string]models.Station, len(stations)),
+		services: make(map[string]models.Service),
+		names:    search.NewIndex(stations),
+		options:  options,
+	}
+	for _, station := range stations {
+		g.stations[station.Name] = station
+	}
+
+	canonical := make(map[string]string)
+	node := func(name string) string {
+		if resolved, ok := canonical[name]; ok {
+			return resolved
+		}
+		resolved := name
+		if station, ok := g.names.Lookup(name); ok {
+			resolved = station.Name
+		}
+		canonical[name] = resolved
+		return resolved
+	}
+
+	stored := make(map[[2]string]bool)
+	for _, route := range routes {
+		stored[[2]string{node(route.From), node(route.To)}] = true
+	}
+
+	for _, route := range routes {
+		reversible := !stored[[2]string{node(route.To), node(route.From)}]
+
+		stops := []string{node(route.From)}
+		if !route.IsDirectRoute {
+			for _, stop := range route.IntermediateStations {
+				stops = append(stops, node(stop))
+			}
+		}
+		stops = append(stops, node(route.To))
+
+		source := models.LegSourceRoute
+		if route.PriceSource == models.PriceSourceGTFSEstimate {
+			source = models.LegSourceEstimate
+		} else if len(stops) > 2 {
+			source = models.LegSourceSplit
+		}
+
+		for _, service := range route.AllServices() {
+			key := serviceKey(route.ID.Hex(), service.VehicleClass)
+			g.services[key] = service
+			segmentPrices := service.Price.Split(len(stops) - 1)
+
+			for i, stop := range stops {
+				onBoard := onBoardNode(key, stop)
+				g.addEdge(stop, edge{to: onBoard, kind: boardEdge, vehicleClass: service.VehicleClass})
+				g.addEdge(onBoard, edge{to: stop, kind: alightEdge})
+				if i > 0 {
+					prev := onBoardNode(key, stops[i-1])
+					g.addEdge(prev, edge{to: onBoard, kind: rideEdge, price: segmentPrices[i-1], source: source})
+					if reversible {
+						g.addEdge(onBoard, edge{to: prev, kind: rideEdge, price: segmentPrices[i-1], source: source})
+					}
+				}
+			}
+		}
+	}
+	return g
+}
+
+func (g *Graph) addEdge(from string, e edge) {
+	g.edges[from] = append(g.edges[from], e)
+}
+
+// serviceKey names a vehicle class running along a route
+func serviceKey(routeID, vehicleClass string) string {
+	return routeID + ":" + vehicleClass
+}
+
+// parseServiceKey splits a service key into its route and vehicle class
+func parseServiceKey(key string) (routeID, vehicleClass string) {
+	routeID, vehicleClass, _ = strings.Cut(key, ":")
+	return routeID, vehicleClass
+}
+
+// onBoardNode names the node of being aboard a service at a station.
+// Station nodes are plain station names, which never contain NUL.
+func onBoardNode(service, station string) string {
+	return service + "\x00" + station
+}
+
+// parseNode splits a node into its station and service; the service is empty for station nodes
+func parseNode(node string) (station, service string) {
+	for i := 0; i < len(node); i++ {
+		if node[i] == 0 {
+			return node[i+1:], node[:i]
+		}
+	}
+	return node, ""
+}
+
+// WithModes returns the graph restricted to the given vehicle classes, or the
+// whole graph when none are given. Restricted graphs are built once and kept.
+func (g *Graph) WithModes(modes []string) *Graph {
+	if len(modes) == 0 {
+		return g
+	}
+	allowed := make(map[string]bool, len(modes))
+	for _, mode := range modes {
+		allowed[mode] = true
+	}
+	names := make([]string, 0, len(allowed))
+	for mode := range allowed {
+		names = append(names, mode)
+	}
+	sort.Strings(names)
+	key := strings.Join(names, ",")
+
+	g.viewsMu.Lock()
+	defer g.viewsMu.Unlock()
+	if view, ok := g.views[key]; ok {
+		return view
+	}
+
+	view := &Graph{
+		edges:    make(map[string][]edge, len(g.edges)),
+		stations: g.stations,
+		services: g.services,
+		names:    g.names,
+		options:  g.options,
+	}
+	for node, edges := range g.edges {
+		kept := make([]edge, 0, len(edges))
+		for _, e := range edges {
+			if e.kind != boardEdge || allowed[e.vehicleClass] {
+				kept = append(kept, e)
+			}
+		}
+		view.edges[node] = kept
+	}
+
+	if g.views == nil {
+		g.views = make(map[string]*Graph)
+	}
+	g.views[key] = view
+	return view
+}
+
+// Station returns the station details for a node, if known
+func (g *Graph) Station(name string) (models.Station, bool) {
+	station, ok := g.stations[name]
+	return station, ok
+}
+
+// HasStation reports whether a station is known or served by any route
+func (g *Graph) HasStation(name string) bool {
+	if _, ok := g.stations[name]; ok {
+		return true
+	}
+	_, ok := g.edges[name]
+	return ok
+}
+
+// Lookup returns the station node a stored name refers to exactly: the name
+// with or without the " Station" suffix, an alias, the slug or the ID
+func (g *Graph) Lookup(name string) (string, bool) {
+	if station, ok := g.names.Lookup(name); ok {
+		return station.Name, true
+	}
+	if g.HasStation(name) {
+		return name, true
+	}
+	return "", false
+}
+
+// Search ranks the stations matching what a rider typed, best first
+func (g *Graph) Search(query string, limit int) []search.Match {
+	return g.names.Search(query, limit)
+}
+
+// Resolve returns the station node a rider most likely means, tolerating
+// typos, other spellings and scripts. When it fails, suggestions lists the
+// closest stations.
+func (g *Graph) Resolve(query string) (name string, suggestions []search.Match, ok bool) {
+	if name, ok := g.Lookup(query); ok {
+		return name, nil, true
+	}
+	station, suggestions, ok := g.names.Resolve(query)
+	if !ok {
+		return "", suggestions, false
+	}
+	return station.Name, suggestions, true
+}
+
+// Serves reports whether any service can be boarded at a station
+func (g *Graph) Serves(station string) bool {
+	for _, e := range g.edges[station] {
+		if e.kind == boardEdge {
+			return true
+		}
+	}
+	return false
+}
+
+// RidesTo reports whether a service boarded at one station rides to another without changing
+func (g *Graph) RidesTo(from, to string) bool {
+	for _, board := range g.edges[from] {
+		if board.kind != boardEdge {
+			continue
+		}
+		_, service := parseNode(board.to)
+		target := onBoardNode(service, to)
+		visited := map[string]bool{board.to: true}
+		queue := []string{board.to}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			if node == target {
+				return true
+			}
+			for _, e := range g.edges[node] {
+				if e.kind == rideEdge && !visited[e.to] {
+					visited[e.to] = true
+					queue = append(queue, e.to)
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ShortestPath returns the cheapest journey between two stations, counting
+// the transfer penalty for every change of vehicle
+func (g *Graph) ShortestPath(from, to string) (*Journey, bool) {
+	path, _ := g.dijkstra(from, to, g.costWeight, nil, nil)
+	if path == nil {
+		return nil, false
+	}
+	journey := g.journey(path)
+	return &journey, true
+}
+
+// weightFunc gives the search cost of following an edge
+type weightFunc func(from string, e edge) float64
+
+// costWeight weighs rides by price and boardings by the transfer penalty.
+// Every journey boards once more than it transfers, so the extra penalty is
+// the same for all of them and does not affect ranking.
+func (g *Graph) costWeight(from string, e edge) float64 {
+	switch e.kind {
+	case rideEdge:
+		return e.price.Birr()
+	case boardEdge:
+		return g.options.TransferPenalty + boardEpsilon
+	}
+	return 0
+}
+
+// dijkstra finds the lowest-cost path between two station nodes using a
+// binary heap, ignoring blocked nodes and edges
+func (g *Graph) dijkstra(from, to string, weight weightFunc, blockedNodes map[string]bool, blockedEdges map[[2]string]bool) ([]string, float64) {
+	if _, ok := g.edges[from]; !ok {
+		return nil, 0
+	}
+
+	distances := map[string]float64{from: 0}
+	previous := make(map[string]string)
+	visited := make(map[string]bool)
+
+	queue := &priorityQueue{{node: from, cost: 0}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(*item)
+		if visited[current.node] {
+			continue
+		}
+		visited[current.node] = true
+		if current.node == to {
+			break
+		}
+
+		for _, e := range g.edges[current.node] {
+			if visited[e.to] || blockedNodes[e.to] || blockedEdges[[2]string{current.node, e.to}] {
+				continue
+			}
+			newDist := current.cost + weight(current.node, e)
+			if dist, seen := distances[e.to]; !seen || newDist < dist {
+				distances[e.to] = newDist
+				previous[e.to] = current.node
+				heap.Push(queue, &item{node: e.to, cost: newDist})
+			}
+		}
+	}
+
+	if !visited[to] {
+		return nil, 0
+	}
+
+	// Reconstruct path
+	path := []string{to}
+	for current := to; current != from; {
+		current = previous[current]
+		path = append([]string{current}, path...)
+	}
+	return path, distances[to]
+}
+
+// findEdge returns the edge between two adjacent nodes
+func (g *Graph) findEdge(from, to string) edge {
+	for _, e := range g.edges[from] {
+		if e.to == to {
+			return e
+		}
+	}
+	return edge{}
+}
+
+type item struct {
+	node string
+	cost float64
+}
+
+// priorityQueue is a min-heap of nodes ordered by cost
+type priorityQueue []*item
+
+func (pq priorityQueue) Len() int            { return len(pq) }
+func (pq priorityQueue) Less(i, j int) bool  { return pq[i].cost < pq[j].cost }
+func (pq priorityQueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
+func (pq *priorityQueue) Push(x interface{}) { *pq = append(*pq, x.(*item)) }
+func (pq *priorityQueue) Pop() interface{} {
+	old := *pq
+	n := len(old)
+	it := old[n-1]
+	*pq = old[:n-1]
+	return it
+}
@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"context"
+	"reflect"
+	"taxi-fare-calculator/database"
+	"taxi-fare-calculator/models"
+	"testing"
+	"time"
+)
+
+// newTestStore stores a direct route from Mexico to Piassa for 30 Birr, and a
+// cheaper journey with a transfer at Sebategna for 10 + 10 Birr
+func newTestStore(t *testing.T) *database.MemoryStore {
+	t.Helper()
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	stations := []models.Station{
+		{Name: "Mexico Station", Location: models.Location{Type: "Point", Coordinates: []float64{38.7450, 9.0107}}},
+		{Name: "Sebategna Station", Location: models.Location{Type: "Point", Coordinates: []float64{38.7380, 9.0350}}},
+		{Name: "Piassa Station", Location: models.Location{Type: "Point", Coordinates: []float64{38.7520, 9.0330}}},
+	}
+	for i := range stations {
+		if err := store.InsertStation(ctx, &stations[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	routes := []models.Route{
+		{From: "Mexico Station", To: "Piassa Station", Price: 30 * models.Birr, IsDirectRoute: true},
+		{From: "Mexico Station", To: "Sebategna Station", Price: 10 * models.Birr, IsDirectRoute: true},
+		{From: "Sebategna Station", To: "Piassa Station", Price: 10 * models.Birr, IsDirectRoute: true},
+	}
+	for i := range routes {
+		if err := store.InsertRoute(ctx, &routes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func buildGraph(t *testing.T, store *database.MemoryStore, penalty float64) *Graph {
+	t.Helper()
+	g, err := NewCache(store, store, time.Minute, Options{TransferPenalty: penalty}).Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestShortestPathTransferPenalty(t *testing.T) {
+	store := newTestStore(t)
+	viaSebategna := []string{"Mexico Station", "Sebategna Station", "Piassa Station"}
+	direct := []string{"Mexico Station", "Piassa Station"}
+
+	tests := []struct {
+		name      string
+		penalty   float64
+		route     []string
+		price     models.Money
+		transfers int
+	}{
+		{"no penalty takes the cheapest", 0, viaSebategna, 20 * models.Birr, 1},
+		{"penalty below the saving", 5, viaSebategna, 20 * models.Birr, 1},
+		{"penalty equal to the saving", 10, direct, 30 * models.Birr, 0},
+		{"penalty above the saving", 15, direct, 30 * models.Birr, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journey, ok := buildGraph(t, store, tt.penalty).ShortestPath("Mexico Station", "Piassa Station")
+			if !ok {
+				t.Fatal("no journey found")
+			}
+			if !reflect.DeepEqual(journey.Route, tt.route) {
+				t.Errorf("route = %v, want %v", journey.Route, tt.route)
+			}
+			// The penalty steers the search but is never charged
+			if journey.TotalPrice != tt.price {
+				t.Errorf("price = %s, want %s", journey.TotalPrice, tt.price)
+			}
+			if journey.Transfers != tt.transfers {
+				t.Errorf("transfers = %d, want %d", journey.Transfers, tt.transfers)
+			}
+		})
+	}
+}
+
+func TestAlternativesTransferPenalty(t *testing.T) {
+	store := newTestStore(t)
+
+	tests := []struct {
+		name      string
+		penalty   float64
+		sortBy    string
+		prices    []models.Money
+		transfers []int
+	}{
+		{"by price", 15, SortPrice, []models.Money{20 * models.Birr, 30 * models.Birr}, []int{1, 0}},
+		{"by price without penalty", 0, SortPrice, []models.Money{20 * models.Birr, 30 * models.Birr}, []int{1, 0}},
+		{"by transfers", 0, SortTransfers, []models.Money{30 * models.Birr, 20 * models.Birr}, []int{0, 1}},
+		{"by distance", 15, SortDistance, []models.Money{30 * models.Birr, 20 * models.Birr}, []int{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journeys := buildGraph(t, store, tt.penalty).Alternatives("Mexico Station", "Piassa Station", 3, tt.sortBy)
+			var prices []models.Money
+			var transfers []int
+			for _, journey := range journeys {
+				prices = append(prices, journey.TotalPrice)
+				transfers = append(transfers, journey.Transfers)
+			}
+			if !reflect.DeepEqual(prices, tt.prices) || !reflect.DeepEqual(transfers, tt.transfers) {
+				t.Errorf("prices = %v, transfers = %v, want %v, %v", prices, transfers, tt.prices, tt.transfers)
+			}
+		})
+	}
+}
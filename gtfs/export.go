@@ -0,0 +1,199 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"taxi-fare-calculator/dataset"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/search"
+	"taxi-fare-calculator/utils"
+	"time"
+)
+
+const (
+	agencyID  = "redat"
+	serviceID = "daily"
+
+	// Minibus taxis run without a timetable, so trips are published as frequencies
+	serviceStart = 5*time.Hour + 30*time.Minute
+	serviceEnd   = 22*time.Hour + 30*time.Minute
+	headwaySecs  = 600 // used when a service has no known frequency
+
+	// averageSpeedKmh is used to estimate stop-to-stop travel times
+	averageSpeedKmh = 20.0
+	// routeTypeBus is the GTFS route_type for buses and minibuses
+	routeTypeBus = 3
+)
+
+// WriteFeed writes the bundle as a zipped GTFS feed.
+// Routes referencing unknown stations are left out of the feed.
+func WriteFeed(w io.Writer, bundle *dataset.Bundle, now time.Time) error {
+	feed := zip.NewWriter(w)
+
+	// Routes may name their stops by any exact name of a station
+	stations := search.NewIndex(bundle.Stations)
+
+	files := []struct {
+		name   string
+		header []string
+		rows   [][]string
+	}{
+		{"agency.txt", []string{"agency_id", "agency_name", "agency_url", "agency_timezone", "agency_lang"}, [][]string{
+			{agencyID, "Redat", "https://redat.vercel.app", "Africa/Addis_Ababa", "en"},
+		}},
+		{"feed_info.txt", []string{"feed_publisher_name", "feed_publisher_url", "feed_lang", "feed_version"}, [][]string{
+			{"Redat", "https://redat.vercel.app", "en", bundle.Hash},
+		}},
+		{"calendar.txt", []string{"service_id", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "start_date", "end_date"}, [][]string{
+			{serviceID, "1", "1", "1", "1", "1", "1", "1", now.Format("20060102"), now.AddDate(1, 0, 0).Format("20060102")},
+		}},
+		{"stops.txt", []string{"stop_id", "stop_name", "stop_lat", "stop_lon", "location_type"}, nil},
+		{"routes.txt", []string{"route_id", "agency_id", "route_short_name", "route_long_name", "route_type"}, nil},
+		{"trips.txt", []string{"route_id", "service_id", "trip_id", "direction_id"}, nil},
+		{"stop_times.txt", []string{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence", "timepoint"}, nil},
+		{"frequencies.txt", []string{"trip_id", "start_time", "end_time", "headway_secs", "exact_times"}, nil},
+		{"fare_attributes.txt", []string{"fare_id", "price", "currency_type", "payment_method", "transfers"}, nil},
+		{"fare_rules.txt", []string{"fare_id", "route_id"}, nil},
+	}
+	rows := make(map[string]*[][]string)
+	for i := range files {
+		rows[files[i].name] = &files[i].rows
+	}
+	add := func(file string, row ...string) {
+		*rows[file] = append(*rows[file], row)
+	}
+
+	for _, station := range bundle.Stations {
+		if len(station.Location.Coordinates) != 2 {
+			continue
+		}
+		add("stops.txt", station.ID.Hex(), station.Name,
+			formatFloat(station.Location.Coordinates[1]), formatFloat(station.Location.Coordinates[0]), "0")
+	}
+
+	// Routes are ridden both ways unless the reverse direction is stored
+	// separately, possibly naming its ends differently
+	canonical := func(name string) string {
+		if station, ok := stations.Lookup(name); ok {
+			return station.Name
+		}
+		return name
+	}
+	stored := make(map[string]bool)
+	for _, route := range bundle.Routes {
+		stored[canonical(route.From)+"\x00"+canonical(route.To)] = true
+	}
+
+	for _, route := range bundle.Routes {
+		names := append([]string{route.From}, route.IntermediateStations...)
+		names = append(names, route.To)
+
+		var stops []models.Station
+		for _, name := range names {
+			station, ok := stations.Lookup(name)
+			if !ok || len(station.Location.Coordinates) != 2 {
+				break
+			}
+			stops = append(stops, station)
+		}
+		if len(stops) != len(names) {
+			log.Printf("⚠️ GTFS export: skipping route %s -> %s, it references an unknown station", route.From, route.To)
+			continue
+		}
+
+		for _, service := range route.AllServices() {
+			if service.VehicleClass == models.VehicleRideHail {
+				// Ride-hail cars do not run as scheduled transit
+				continue
+			}
+
+			// Minibus services keep the plain route ID used before vehicle classes
+			routeID := route.ID.Hex()
+			name := fmt.Sprintf("%s - %s", route.From, route.To)
+			if service.VehicleClass != models.VehicleMinibus {
+				routeID += "-" + service.VehicleClass
+				name += " (" + service.VehicleClass + ")"
+			}
+			fareID := routeID + "-fare"
+			headway := headwaySecs
+			if service.FrequencyMinutes > 0 {
+				headway = service.FrequencyMinutes * 60
+			}
+
+			add("routes.txt", routeID, agencyID, "", name, strconv.Itoa(routeTypeBus))
+			add("fare_attributes.txt", fareID, service.Price.String(), "ETB", "0", "0")
+			add("fare_rules.txt", fareID, routeID)
+
+			addTrip := func(tripID, direction string, stops []models.Station) {
+				add("trips.txt", routeID, serviceID, tripID, direction)
+				add("frequencies.txt", tripID, formatTime(serviceStart), formatTime(serviceEnd), strconv.Itoa(headway), "0")
+
+				offset := serviceStart
+				for i, stop := range stops {
+					if i > 0 {
+						offset += travelTime(stops[i-1], stop)
+					}
+					add("stop_times.txt", tripID, formatTime(offset), formatTime(offset), stop.ID.Hex(), strconv.Itoa(i+1), "0")
+				}
+			}
+
+			addTrip(routeID+"-trip", "0", stops)
+			if !stored[stops[len(stops)-1].Name+"\x00"+stops[0].Name] {
+				reversed := make([]models.Station, len(stops))
+				for i, stop := range stops {
+					reversed[len(stops)-1-i] = stop
+				}
+				addTrip(routeID+"-return", "1", reversed)
+			}
+		}
+	}
+
+	for _, file := range files {
+		if err := writeCSV(feed, file.name, file.header, file.rows); err != nil {
+			return err
+		}
+	}
+	return feed.Close()
+}
+
+func writeCSV(feed *zip.Writer, name string, header []string, rows [][]string) error {
+	w, err := feed.Create(name)
+	if err != nil {
+		return err
+	}
+	out := csv.NewWriter(w)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	if err := out.WriteAll(rows); err != nil {
+		return fmt.Errorf("error writing %s: %v", name, err)
+	}
+	return nil
+}
+
+// travelTime estimates the ride time between two consecutive stops, at least one minute
+func travelTime(from, to models.Station) time.Duration {
+	km := utils.HaversineDistance(
+		from.Location.Coordinates[1], from.Location.Coordinates[0],
+		to.Location.Coordinates[1], to.Location.Coordinates[0],
+	)
+	minutes := int(km/averageSpeedKmh*60 + 0.5)
+	if minutes < 1 {
+		minutes = 1
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// formatTime renders an offset from midnight as GTFS HH:MM:SS
+func formatTime(d time.Duration) string {
+	secs := int(d.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,82 @@
+package gtfs
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"taxi-fare-calculator/dataset"
+	"taxi-fare-calculator/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFeedRoundTrip(t *testing.T) {
+	station := func(name string, lng, lat float64) models.Station {
+		return models.Station{
+			ID:       primitive.NewObjectID(),
+			Name:     name,
+			Location: models.Location{Type: "Point", Coordinates: []float64{lng, lat}},
+		}
+	}
+	bundle := &dataset.Bundle{
+		Stations: []models.Station{
+			station("Mexico Station", 38.7450, 9.0107),
+			station("Sebategna Station", 38.7380, 9.0350),
+			station("Piassa Station", 38.7520, 9.0330),
+		},
+		Routes: []models.Route{
+			{ID: primitive.NewObjectID(), From: "Mexico Station", To: "Piassa Station", Price: 20 * models.Birr,
+				IntermediateStations: []string{"Sebategna Station"}},
+			// Routes may name stations without the suffix
+			{ID: primitive.NewObjectID(), From: "Sebategna", To: "Piassa Station", Price: 1050, IsDirectRoute: true},
+		},
+	}
+
+	var feed bytes.Buffer
+	if err := WriteFeed(&feed, bundle, time.Date(2025, time.March, 4, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	estimate := func(distance float64) (models.Money, error) {
+		t.Errorf("fare estimated for a %.1f km route with a published fare", distance)
+		return 0, nil
+	}
+	imported, conversion, err := ReadFeed(bytes.NewReader(feed.Bytes()), int64(feed.Len()), estimate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversion.Skipped) > 0 || len(conversion.FaresEstimated) > 0 {
+		t.Errorf("conversion = %+v, want nothing skipped or estimated", conversion)
+	}
+
+	var stations []string
+	for _, s := range imported.Stations {
+		stations = append(stations, s.Name)
+		for _, original := range bundle.Stations {
+			if s.Name == original.Name && !reflect.DeepEqual(s.Location.Coordinates, original.Location.Coordinates) {
+				t.Errorf("%s is at %v, want %v", s.Name, s.Location.Coordinates, original.Location.Coordinates)
+			}
+		}
+	}
+	sort.Strings(stations)
+	if want := []string{"Mexico Station", "Piassa Station", "Sebategna Station"}; !reflect.DeepEqual(stations, want) {
+		t.Errorf("stations = %v, want %v", stations, want)
+	}
+
+	var routes []string
+	for _, route := range imported.Routes {
+		routes = append(routes, route.From+" -> "+route.To+" "+route.Price.String())
+		if route.From == "Mexico Station" && !reflect.DeepEqual(route.IntermediateStations, []string{"Sebategna Station"}) {
+			t.Errorf("%s -> %s stops at %v, want [Sebategna Station]", route.From, route.To, route.IntermediateStations)
+		}
+	}
+	sort.Strings(routes)
+	want := []string{
+		"Mexico Station -> Piassa Station 20",
+		"Sebategna Station -> Piassa Station 10.5",
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("routes = %v, want %v", routes, want)
+	}
+}
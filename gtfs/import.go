@@ -0,0 +1,360 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"taxi-fare-calculator/dataset"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/utils"
+)
+
+// Conversion describes how a GTFS feed was mapped onto stations and routes
+type Conversion struct {
+	Merged         []string `json:"merged"`
+	Skipped        []string `json:"skipped"`
+	FaresEstimated []string `json:"fares_estimated"`
+}
+
+// MaxEntrySize is the largest uncompressed size of a file read from a feed,
+// so a small zip cannot expand into gigabytes of CSV
+const MaxEntrySize = 64 << 20
+
+// ErrEntryTooLarge is returned when a file in a feed exceeds MaxEntrySize
+var ErrEntryTooLarge = errors.New("GTFS feed file is too large")
+
+type table []map[string]string
+
+type fareRule struct {
+	fareID, routeID, origin, destination string
+}
+
+// ReadFeed converts a zipped GTFS feed into a dataset bundle.
+// Stop names get the " Station" suffix used throughout the API, stops sharing a
+// parent station collapse into it, and each distinct stop pattern of a GTFS route
+// becomes one route. Routes without a fare are priced from their length in
+// kilometers with the given fare function.
+func ReadFeed(r io.ReaderAt, size int64, fare func(distance float64) (models.Money, error)) (*dataset.Bundle, *Conversion, error) {
+	feed, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid GTFS zip: %v", err)
+	}
+	for _, file := range feed.File {
+		if file.UncompressedSize64 > MaxEntrySize {
+			return nil, nil, fmt.Errorf("%w: %s is %d bytes, the limit is %d", ErrEntryTooLarge, file.Name, file.UncompressedSize64, MaxEntrySize)
+		}
+	}
+
+	tables := make(map[string]table)
+	for _, name := range []string{"stops.txt", "routes.txt", "trips.txt", "stop_times.txt", "fare_attributes.txt", "fare_rules.txt"} {
+		t, err := readTable(feed, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		tables[name] = t
+	}
+	for _, required := range []string{"stops.txt", "routes.txt", "trips.txt", "stop_times.txt"} {
+		if len(tables[required]) == 0 {
+			return nil, nil, fmt.Errorf("GTFS feed has no %s", required)
+		}
+	}
+
+	conv := &Conversion{}
+	bundle := &dataset.Bundle{}
+
+	// Stops become stations, keyed by the GTFS stop_id they were read from
+	stopsByID := make(map[string]map[string]string)
+	for _, stop := range tables["stops.txt"] {
+		stopsByID[stop["stop_id"]] = stop
+	}
+	stationOf := make(map[string]string)
+	zoneOf := make(map[string]string)
+	stationIndex := make(map[string]int)
+	for _, stop := range tables["stops.txt"] {
+		id := stop["stop_id"]
+		zoneOf[id] = stop["zone_id"]
+
+		switch stop["location_type"] {
+		case "", "0", "1":
+		default:
+			conv.Skipped = append(conv.Skipped, fmt.Sprintf("stop %s (%s): not a boarding location", id, stop["stop_name"]))
+			continue
+		}
+
+		source := stop
+		if parent, ok := stopsByID[stop["parent_station"]]; ok {
+			source = parent
+		}
+		name := stationName(source["stop_name"])
+		lat, errLat := strconv.ParseFloat(source["stop_lat"], 64)
+		lng, errLng := strconv.ParseFloat(source["stop_lon"], 64)
+		if name == " Station" || errLat != nil || errLng != nil {
+			conv.Skipped = append(conv.Skipped, fmt.Sprintf("stop %s: missing name or coordinates", id))
+			continue
+		}
+
+		stationOf[id] = name
+		if _, ok := stationIndex[name]; ok {
+			if source["stop_id"] != id || stop["location_type"] != "1" {
+				conv.Merged = append(conv.Merged, fmt.Sprintf("stop %s merged into %s", id, name))
+			}
+			continue
+		}
+		stationIndex[name] = len(bundle.Stations)
+		bundle.Stations = append(bundle.Stations, models.Station{
+			Name: name,
+			Location: models.Location{
+				Type:        "Point",
+				Coordinates: []float64{lng, lat},
+			},
+		})
+	}
+
+	// Collect the ordered stop pattern of every trip
+	type stopTime struct {
+		sequence int
+		stopID   string
+	}
+	tripStops := make(map[string][]stopTime)
+	for _, st := range tables["stop_times.txt"] {
+		seq, err := strconv.Atoi(st["stop_sequence"])
+		if err != nil {
+			continue
+		}
+		tripStops[st["trip_id"]] = append(tripStops[st["trip_id"]], stopTime{seq, st["stop_id"]})
+	}
+
+	routeTrips := make(map[string]table)
+	for _, trip := range tables["trips.txt"] {
+		routeTrips[trip["route_id"]] = append(routeTrips[trip["route_id"]], trip)
+	}
+
+	fares := make(map[string]float64)
+	for _, fare := range tables["fare_attributes.txt"] {
+		if price, err := strconv.ParseFloat(fare["price"], 64); err == nil {
+			fares[fare["fare_id"]] = price
+		}
+	}
+	var rules []fareRule
+	for _, rule := range tables["fare_rules.txt"] {
+		rules = append(rules, fareRule{rule["fare_id"], rule["route_id"], rule["origin_id"], rule["destination_id"]})
+	}
+
+	routeKeys := make(map[string]int)
+	for _, gtfsRoute := range tables["routes.txt"] {
+		routeID := gtfsRoute["route_id"]
+		label := routeLabel(gtfsRoute)
+
+		trips := routeTrips[routeID]
+		if len(trips) == 0 {
+			conv.Skipped = append(conv.Skipped, fmt.Sprintf("route %s: no trips", label))
+			continue
+		}
+		// Outbound trips first so their direction is the one kept
+		sort.SliceStable(trips, func(i, j int) bool {
+			if trips[i]["direction_id"] != trips[j]["direction_id"] {
+				return trips[i]["direction_id"] < trips[j]["direction_id"]
+			}
+			return trips[i]["trip_id"] < trips[j]["trip_id"]
+		})
+
+		// Distinct stop patterns of this route, reversals of an earlier pattern included
+		var patterns [][]string
+		seen := make(map[string]bool)
+		for _, trip := range trips {
+			tripID := trip["trip_id"]
+			stopTimes := tripStops[tripID]
+			sort.Slice(stopTimes, func(i, j int) bool { return stopTimes[i].sequence < stopTimes[j].sequence })
+
+			var stopIDs []string
+			var names []string
+			valid := true
+			for _, st := range stopTimes {
+				name, ok := stationOf[st.stopID]
+				if !ok {
+					valid = false
+					break
+				}
+				if len(names) > 0 && names[len(names)-1] == name {
+					continue
+				}
+				names = append(names, name)
+				stopIDs = append(stopIDs, st.stopID)
+			}
+			if !valid || len(names) < 2 {
+				conv.Skipped = append(conv.Skipped, fmt.Sprintf("trip %s of route %s: fewer than two known stops", tripID, label))
+				continue
+			}
+
+			key := strings.Join(names, "\x00")
+			if seen[key] {
+				continue
+			}
+			reversed := make([]string, len(names))
+			for i, name := range names {
+				reversed[len(names)-1-i] = name
+			}
+			if seen[strings.Join(reversed, "\x00")] {
+				conv.Merged = append(conv.Merged, fmt.Sprintf("trip %s of route %s is the return direction of an imported pattern", tripID, label))
+				seen[key] = true
+				continue
+			}
+			seen[key] = true
+			patterns = append(patterns, append([]string{stopIDs[0], stopIDs[len(stopIDs)-1]}, names...))
+		}
+
+		for _, pattern := range patterns {
+			firstStop, lastStop, names := pattern[0], pattern[1], pattern[2:]
+			if hasRepeats(names) {
+				conv.Skipped = append(conv.Skipped, fmt.Sprintf("route %s: pattern %s visits a station twice", label, strings.Join(names, " -> ")))
+				continue
+			}
+
+			route := models.Route{
+				From:          names[0],
+				To:            names[len(names)-1],
+				IsDirectRoute: len(names) == 2,
+			}
+			if len(names) > 2 {
+				route.IntermediateStations = append([]string(nil), names[1:len(names)-1]...)
+			}
+
+			price, ok := lookupFare(rules, fares, routeID, zoneOf[firstStop], zoneOf[lastStop])
+			route.Price = models.FromBirr(price)
+			route.PriceSource = "gtfs fare"
+			if !ok {
+				route.Price, err = fare(patternDistance(bundle.Stations, stationIndex, names))
+				if err != nil {
+					return nil, nil, fmt.Errorf("cannot estimate the fare of %s -> %s: %v", route.From, route.To, err)
+				}
+				route.PriceSource = models.PriceSourceGTFSEstimate
+				conv.FaresEstimated = append(conv.FaresEstimated, fmt.Sprintf("%s -> %s: %s Birr", route.From, route.To, route.Price))
+			}
+
+			key := route.From + "\x00" + route.To
+			if i, ok := routeKeys[key]; ok {
+				// Keep the pattern serving the most stops between the same endpoints
+				if len(route.IntermediateStations) > len(bundle.Routes[i].IntermediateStations) {
+					bundle.Routes[i] = route
+				}
+				conv.Merged = append(conv.Merged, fmt.Sprintf("route %s: pattern %s merged with another %s -> %s pattern", label, strings.Join(names, " -> "), route.From, route.To))
+				continue
+			}
+			routeKeys[key] = len(bundle.Routes)
+			bundle.Routes = append(bundle.Routes, route)
+		}
+	}
+
+	return bundle, conv, nil
+}
+
+// readTable reads a CSV file of the feed into rows keyed by column name; missing files are empty
+func readTable(feed *zip.Reader, name string) (table, error) {
+	file, err := feed.Open(name)
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	// The declared size can lie, so stop reading past the limit as well
+	limited := &io.LimitedReader{R: file, N: MaxEntrySize + 1}
+	reader := csv.NewReader(limited)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if limited.N <= 0 {
+		return nil, fmt.Errorf("%w: %s is over %d bytes", ErrEntryTooLarge, name, MaxEntrySize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", name, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	var rows table
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// stationName applies the API's "<Name> Station" naming convention
+func stationName(stopName string) string {
+	return strings.TrimSuffix(strings.TrimSpace(stopName), " Station") + " Station"
+}
+
+func routeLabel(route map[string]string) string {
+	for _, key := range []string{"route_short_name", "route_long_name"} {
+		if route[key] != "" {
+			return fmt.Sprintf("%s (%s)", route["route_id"], route[key])
+		}
+	}
+	return route["route_id"]
+}
+
+func hasRepeats(names []string) bool {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			return true
+		}
+		seen[name] = true
+	}
+	return false
+}
+
+// lookupFare finds the most specific fare rule matching the route and its end zones
+func lookupFare(rules []fareRule, fares map[string]float64, routeID, originZone, destinationZone string) (float64, bool) {
+	best := -1
+	var price float64
+	for _, rule := range rules {
+		fare, ok := fares[rule.fareID]
+		if !ok {
+			continue
+		}
+		if (rule.routeID != "" && rule.routeID != routeID) ||
+			(rule.origin != "" && rule.origin != originZone) ||
+			(rule.destination != "" && rule.destination != destinationZone) {
+			continue
+		}
+
+		score := 0
+		for _, field := range []string{rule.routeID, rule.origin, rule.destination} {
+			if field != "" {
+				score++
+			}
+		}
+		if score > best {
+			best = score
+			price = fare
+		}
+	}
+	return price, best >= 0
+}
+
+// patternDistance sums the straight-line distance in kilometers along the named stations
+func patternDistance(stations []models.Station, index map[string]int, names []string) float64 {
+	var total float64
+	for i := 1; i < len(names); i++ {
+		from := stations[index[names[i-1]]].Location.Coordinates
+		to := stations[index[names[i]]].Location.Coordinates
+		total += utils.HaversineDistance(from[1], from[0], to[1], to[0])
+	}
+	return total
+}
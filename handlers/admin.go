@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireAdmin only lets through requests carrying the configured admin token
+// as a bearer token. Admin endpoints are disabled when no token is configured.
+// It guards every endpoint that writes tariffs, surcharges, holidays or fare
+// events, or imports data in bulk.
+func (h *Handler) RequireAdmin(c *fiber.Ctx) error {
+	if h.Config.AdminToken == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Admin API is disabled",
+		})
+	}
+
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.Config.AdminToken)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid admin token",
+		})
+	}
+	return c.Next()
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"taxi-fare-calculator/calendar"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// eventWindow is how far ahead upcoming fare events are listed by default
+const eventWindow = 30 * 24 * time.Hour
+
+// GetCalendarDay describes a day in both calendars with its holidays and fare
+// events. The day is given as 'date' in the calendar named by 'calendar'
+// (gregorian by default), and defaults to today.
+func (h *Handler) GetCalendarDay(c *fiber.Ctx) error {
+	date := c.Query("date", h.Fares.LocalDate(time.Time{}))
+	if c.Query("calendar") == models.CalendarEthiopian {
+		ethiopian, err := calendar.Parse(date)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		date = ethiopian.Gregorian().Format("2006-01-02")
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "'date' must be formatted as YYYY-MM-DD",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	holiday, err := h.Fares.IsHoliday(ctx, date)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching holidays",
+		})
+	}
+	events, err := h.Events.ListFareEvents(ctx, date, date)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching fare events",
+		})
+	}
+	for i := range events {
+		events[i].SetEthiopianDates()
+	}
+
+	ethiopian := calendar.FromGregorian(day)
+	return c.JSON(fiber.Map{
+		"date":            date,
+		"ethiopian":       ethiopian.String(),
+		"ethiopian_label": ethiopian.Format(),
+		"holiday":         holiday,
+		"holidays":        calendar.HolidaysOn(date),
+		"events":          events,
+	})
+}
+
+// GetEthiopianHolidays lists the fixed public holidays of an Ethiopian year,
+// the current one by default
+func (h *Handler) GetEthiopianHolidays(c *fiber.Ctx) error {
+	year := c.QueryInt("year", calendar.FromGregorian(time.Now()).Year)
+	return c.JSON(fiber.Map{
+		"year":     year,
+		"holidays": calendar.Holidays(year),
+	})
+}
+
+// GetFareEvents lists fare events overlapping 'from' to 'to' (YYYY-MM-DD),
+// by default those in force today or in the coming 30 days
+func (h *Handler) GetFareEvents(c *fiber.Ctx) error {
+	from := c.Query("from", h.Fares.LocalDate(time.Time{}))
+	to := c.Query("to", h.Fares.LocalDate(time.Now().Add(eventWindow)))
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "'from' and 'to' must be formatted as YYYY-MM-DD",
+			})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := h.Events.ListFareEvents(ctx, from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching fare events",
+		})
+	}
+	for i := range events {
+		events[i].SetEthiopianDates()
+	}
+
+	return c.JSON(fiber.Map{
+		"events": events,
+	})
+}
+
+func (h *Handler) AddFareEvent(c *fiber.Ctx) error {
+	event := new(models.FareEvent)
+	if err := c.BodyParser(event); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot parse JSON",
+		})
+	}
+
+	if err := event.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, routeID := range event.SuspendedRoutes {
+		if _, err := h.Routes.GetRoute(ctx, routeID); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Unknown route " + routeID.Hex(),
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error fetching route",
+			})
+		}
+	}
+
+	if err := h.Events.InsertFareEvent(ctx, event); err != nil {
+		log.Printf("❌ Error creating fare event: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error creating fare event",
+		})
+	}
+
+	event.SetEthiopianDates()
+	return c.Status(fiber.StatusCreated).JSON(event)
+}
+
+func (h *Handler) DeleteFareEvent(c *fiber.Ctx) error {
+	id := c.Params("id")
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = h.Events.DeleteFareEvent(ctx, objectId)
+	if errors.Is(err, models.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Fare event not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error deleting fare event",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Fare event deleted successfully",
+	})
+}
@@ -4,14 +4,12 @@ import (
 	"context"
 	"fmt"
 	"mime/multipart"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/gofiber/fiber/v2"
-	"github.com/resendlabs/resend-go"
 )
 
 type Contribution struct {
@@ -22,7 +20,7 @@ type Contribution struct {
 	Notes                string   `form:"notes"`
 }
 
-func HandleContribution(c *fiber.Ctx) error {
+func (h *Handler) HandleContribution(c *fiber.Ctx) error {
 	// Get form values
 	startStation := c.FormValue("startStation")
 	endStation := c.FormValue("endStation")
@@ -42,7 +40,7 @@ func HandleContribution(c *fiber.Ctx) error {
 
 	// Handle file uploads
 	if startStationFile, err := c.FormFile("startStationImage"); err == nil && startStationFile != nil {
-		url, err := uploadImage(startStationFile)
+		url, err := h.uploadImage(startStationFile)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": fmt.Sprintf("Failed to upload start station image: %v", err),
@@ -52,7 +50,7 @@ func HandleContribution(c *fiber.Ctx) error {
 	}
 
 	if endStationFile, err := c.FormFile("endStationImage"); err == nil && endStationFile != nil {
-		url, err := uploadImage(endStationFile)
+		url, err := h.uploadImage(endStationFile)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": fmt.Sprintf("Failed to upload end station image: %v", err),
@@ -66,7 +64,7 @@ func HandleContribution(c *fiber.Ctx) error {
 	if err == nil {
 		for key, files := range form.File {
 			if strings.HasPrefix(key, "intermediateStationImage") && len(files) > 0 {
-				url, err := uploadImage(files[0])
+				url, err := h.uploadImage(files[0])
 				if err != nil {
 					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 						"error": fmt.Sprintf("Failed to upload intermediate station image: %v", err),
@@ -137,24 +135,16 @@ func HandleContribution(c *fiber.Ctx) error {
 		}
 	}
 
-	// Send email using Resend
-	client := resend.NewClient(os.Getenv("RESEND_API_KEY"))
-
-	adminEmail := os.Getenv("ADMIN_EMAIL")
+	// Send email to the admin
+	adminEmail := h.Config.AdminEmail
 	if adminEmail == "" {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Admin email not configured",
 		})
 	}
 
-	params := &resend.SendEmailRequest{
-		From:    "Redat Contributions <onboarding@resend.dev>",
-		To:      []string{adminEmail},
-		Subject: fmt.Sprintf("New Route Contribution: %s to %s", startStation, endStation),
-		Html:    emailBody.String(),
-	}
-
-	_, err = client.Emails.Send(params)
+	subject := fmt.Sprintf("New Route Contribution: %s to %s", startStation, endStation)
+	err = h.Mailer.Send([]string{adminEmail}, subject, emailBody.String())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to send email: %v", err),
@@ -166,9 +156,9 @@ func HandleContribution(c *fiber.Ctx) error {
 	})
 }
 
-func uploadImage(file *multipart.FileHeader) (string, error) {
+func (h *Handler) uploadImage(file *multipart.FileHeader) (string, error) {
 	// Initialize Cloudinary
-	cloudinaryURL := os.Getenv("CLOUDINARY_URL")
+	cloudinaryURL := h.Config.CloudinaryURL
 	if cloudinaryURL == "" {
 		return "", fmt.Errorf("CLOUDINARY_URL not configured")
 	}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"taxi-fare-calculator/dataset"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) ExportDataset(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	bundle, err := dataset.Export(ctx, h.Stations, h.Routes)
+	if err != nil {
+		log.Printf("❌ Error exporting dataset: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error exporting dataset",
+		})
+	}
+
+	c.Set(fiber.HeaderETag, fmt.Sprintf("%q", bundle.Hash))
+	if c.QueryBool("download") {
+		filename := fmt.Sprintf("redat-export-%s.json", bundle.ExportedAt.Format("20060102-150405"))
+		c.Attachment(filename)
+	}
+	return c.JSON(bundle)
+}
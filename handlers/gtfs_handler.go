@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"taxi-fare-calculator/dataset"
+	"taxi-fare-calculator/gtfs"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) ExportGTFS(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	bundle, err := dataset.Export(ctx, h.Stations, h.Routes)
+	if err != nil {
+		log.Printf("❌ Error exporting dataset: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error exporting dataset",
+		})
+	}
+
+	var feed bytes.Buffer
+	if err := gtfs.WriteFeed(&feed, bundle, time.Now()); err != nil {
+		log.Printf("❌ Error writing GTFS feed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error writing GTFS feed",
+		})
+	}
+
+	c.Attachment("redat-gtfs.zip")
+	c.Type("zip")
+	return c.Send(feed.Bytes())
+}
+
+// maxFeedUpload is the largest GTFS zip accepted for import
+const maxFeedUpload = 4 << 20
+
+func (h *Handler) ImportGTFS(c *fiber.Ctx) error {
+	file, err := c.FormFile("feed")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A GTFS zip must be uploaded in the 'feed' field",
+		})
+	}
+	if file.Size > maxFeedUpload {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": fmt.Sprintf("GTFS feed must be at most %d MB", maxFeedUpload>>20),
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot read uploaded feed",
+		})
+	}
+	defer src.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tariff, err := h.Fares.TariffAt(ctx, models.VehicleMinibus, time.Now())
+	if err != nil {
+		log.Printf("❌ Error loading tariff: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error loading tariff",
+		})
+	}
+
+	bundle, conversion, err := gtfs.ReadFeed(src, file.Size, tariff.Fare)
+	if errors.Is(err, gtfs.ErrEntryTooLarge) {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	report, err := dataset.Import(ctx, h.Stations, h.Routes, bundle, dataset.ImportOptions{DryRun: c.QueryBool("dry_run")})
+	if errors.Is(err, dataset.ErrInvalidBundle) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":      err.Error(),
+			"conversion": conversion,
+			"import":     report,
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Error importing GTFS feed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error importing GTFS feed",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"conversion": conversion,
+		"import":     report,
+	})
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"taxi-fare-calculator/app"
+)
+
+// Handler serves the HTTP API using the shared application dependencies
+type Handler struct {
+	*app.App
+}
+
+func New(a *app.App) *Handler {
+	return &Handler{App: a}
+}
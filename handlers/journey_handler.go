@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"taxi-fare-calculator/graph"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxAlternatives = 10
+
+// JourneyView is how every journey endpoint renders a planned journey: the
+// journey itself with the names of its stations in order
+type JourneyView struct {
+	Route []string `json:"route"`
+	models.Journey
+}
+
+func newJourneyView(journey *models.Journey) JourneyView {
+	return JourneyView{Route: journey.StationNames(), Journey: *journey}
+}
+
+func (h *Handler) GetJourneys(c *fiber.Ctx) error {
+	k := c.QueryInt("k", 3)
+	sortBy := c.Query("sort", graph.SortPrice)
+
+	req, err := parseJourneyQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if k < 1 || k > maxAlternatives {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("'k' must be between 1 and %d", maxAlternatives),
+		})
+	}
+	if !graph.ValidSort(sortBy) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "'sort' must be one of price, transfers, distance",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	journeys, err := h.Planner.Alternatives(ctx, req, k, sortBy)
+	if err != nil {
+		return planError(c, err)
+	}
+
+	views := make([]JourneyView, len(journeys))
+	for i := range journeys {
+		views[i] = newJourneyView(&journeys[i])
+	}
+	return c.JSON(fiber.Map{
+		"journeys": views,
+		"sort":     sortBy,
+	})
+}
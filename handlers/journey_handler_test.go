@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"taxi-fare-calculator/app"
+	"taxi-fare-calculator/config"
+	"taxi-fare-calculator/models"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newTestServer serves the journey endpoints from in-memory storage holding
+// a route from Mexico to Piassa
+func newTestServer(t *testing.T) *fiber.App {
+	t.Helper()
+	a, err := app.New(&config.Config{
+		Storage:        "memory",
+		DetourFactor:   1.4,
+		SnapCandidates: 3,
+		MaxWalk:        1500,
+		WalkPenalty:    1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, station := range []models.Station{
+		{Name: "Mexico Station", Location: models.Location{Type: "Point", Coordinates: []float64{38.7450, 9.0107}}},
+		{Name: "Piassa Station", Location: models.Location{Type: "Point", Coordinates: []float64{38.7520, 9.0330}}},
+	} {
+		if err := a.Stations.InsertStation(ctx, &station); err != nil {
+			t.Fatal(err)
+		}
+	}
+	route := models.Route{From: "Mexico Station", To: "Piassa Station", Price: 15 * models.Birr, IsDirectRoute: true}
+	if err := a.Routes.InsertRoute(ctx, &route); err != nil {
+		t.Fatal(err)
+	}
+
+	h := New(a)
+	server := fiber.New()
+	server.Get("/route", h.GetRoute)
+	server.Get("/journey", h.CalculateJourney)
+	server.Get("/journeys", h.GetJourneys)
+	server.Get("/route-map", h.GetRouteWithMap)
+	return server
+}
+
+func get(t *testing.T, server *fiber.App, url string) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := server.Test(httptest.NewRequest("GET", url, nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestJourneyEndpointsShareOneView(t *testing.T) {
+	server := newTestServer(t)
+	query := "?from=Mexico&to=Piassa&at=2030-03-04T09:00:00Z"
+
+	tests := []struct {
+		name string
+		url  string
+		// listed is set when the journey is the first of a list of alternatives
+		listed bool
+	}{
+		{"route", "/route" + query, false},
+		{"journey", "/journey" + query, false},
+		{"route map", "/route-map" + query, false},
+		{"alternatives", "/journeys" + query + "&k=1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(t, server, tt.url)
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d: %v", status, body)
+			}
+			journey := body
+			if tt.listed {
+				journey = body["journeys"].([]interface{})[0].(map[string]interface{})
+			}
+			route, _ := json.Marshal(journey["route"])
+			if string(route) != `["Mexico Station","Piassa Station"]` {
+				t.Errorf("route = %s", route)
+			}
+			if journey["total_price"] != 15.0 {
+				t.Errorf("total_price = %v, want 15", journey["total_price"])
+			}
+			for _, key := range []string{"stations", "rides", "legs", "transfers", "is_night", "confidence"} {
+				if _, ok := journey[key]; !ok {
+					t.Errorf("%s is missing", key)
+				}
+			}
+		})
+	}
+}
+
+func TestJourneyQueryErrors(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"missing end", "?from=Mexico", "Both 'from' and 'to' parameters are required"},
+		{"bad time", "?from=Mexico&to=Piassa&at=yesterday", "'at' must be an RFC 3339 timestamp"},
+		{"bad coordinates", "?from_lat=91&from_lng=38.7&to=Piassa", "'from_lat' and 'from_lng' must be valid coordinates"},
+	}
+	for _, tt := range tests {
+		for _, path := range []string{"/route", "/journey", "/journeys", "/route-map"} {
+			t.Run(tt.name+" "+path, func(t *testing.T) {
+				status, body := get(t, server, path+tt.query)
+				if status != fiber.StatusBadRequest || body["error"] != tt.want {
+					t.Errorf("got %d %v, want 400 %q", status, body["error"], tt.want)
+				}
+			})
+		}
+	}
+	t.Run("bad modes", func(t *testing.T) {
+		status, _ := get(t, server, "/route?from=Mexico&to=Piassa&modes=camel")
+		if status != fiber.StatusBadRequest {
+			t.Errorf("status = %d, want 400", status)
+		}
+	})
+}
@@ -1,99 +1,179 @@
 package handlers
 
 import (
-	"taxi-fare-calculator/database"
+	"context"
+	"fmt"
+	"log"
 	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/planner"
 	"taxi-fare-calculator/utils"
-
-	"log"
-	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// RouteResponse is the journey view with the trip drawn on the map
 type RouteResponse struct {
-	Route      []models.Station  `json:"route"`
-	Path       interface{}       `json:"path"`
-	TotalPrice float64           `json:"total_price"`
-	Distance   float64           `json:"distance"`
-	Duration   float64           `json:"duration"`
-	Legs       []models.RouteLeg `json:"legs"`
+	JourneyView
+	Path interface{} `json:"path"`
+	// Distance in meters and Duration in seconds cover the walks and the
+	// rides along the roads
+	Distance float64 `json:"distance"`
+	Duration float64 `json:"duration"`
+	// Geometry draws the whole trip: the walk to the first station, every
+	// ride leg and the stations
+	Geometry *utils.FeatureCollection `json:"geometry"`
+	// Polyline is the whole trip as an encoded polyline, when requested
+	Polyline string `json:"polyline,omitempty"`
 }
 
-func GetRouteWithMap(c *fiber.Ctx) error {
-	from := c.Query("from")
-	to := c.Query("to")
-	userLat := c.QueryFloat("user_lat", 0)
-	userLng := c.QueryFloat("user_lng", 0)
+func (h *Handler) GetRouteWithMap(c *fiber.Ctx) error {
+	withPolyline := c.QueryBool("polyline", false)
 
-	if from == "" || to == "" {
+	req, err := parseJourneyQuery(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Both 'from' and 'to' parameters are required",
+			"error": err.Error(),
 		})
 	}
-
-	// Convert place names to station names only if they don't already end with "Station"
-	fromStation := from
-	toStation := to
-	if !strings.HasSuffix(from, "Station") {
-		fromStation = from + " Station"
-	}
-	if !strings.HasSuffix(to, "Station") {
-		toStation = to + " Station"
+	user, err := parsePoint(c, "user")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
 
-	// Log the actual station names being used
-	log.Printf("Converting route from %s to %s", fromStation, toStation)
+	log.Printf("Converting route from %s to %s", describeEnd(req.From, req.FromPoint), describeEnd(req.To, req.ToPoint))
 
-	collection := database.GetCollection("taxi_fare_db", "routes")
-	journey, err := models.CalculateJourney(fromStation, toStation, collection)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	journey, err := h.Planner.Plan(ctx, req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return planError(c, err)
 	}
 
-	// Initialize map service
-	mapService := utils.NewMapService()
+	completeRoute := RouteResponse{JourneyView: newJourneyView(journey)}
 
-	var completeRoute RouteResponse
-	completeRoute.Route = journey.Stations
-	completeRoute.TotalPrice = journey.TotalPrice
-	completeRoute.Legs = journey.Legs
+	geometry := utils.NewFeatureCollection()
+	var trip [][]float64
+	addLine := func(line *utils.LineString, properties map[string]interface{}) {
+		if withPolyline {
+			properties["polyline"] = utils.EncodePolyline(line.Coordinates)
+		}
+		geometry.Features = append(geometry.Features, utils.NewFeature(line, properties))
+		for _, position := range line.Coordinates {
+			if n := len(trip); n > 0 && len(position) == 2 && trip[n-1][0] == position[0] && trip[n-1][1] == position[1] {
+				continue
+			}
+			trip = append(trip, position)
+		}
+	}
 
-	// If user location is provided, get route to first station
-	if userLat != 0 && userLng != 0 {
+	// addWalk draws a planned walk straight, as the planner estimated it; the
+	// router only knows driving routes, which may take roads closed to walkers
+	// and miss footpaths
+	addWalk := func(walk *models.Walk, properties map[string]interface{}) {
+		line := &utils.LineString{Type: "LineString", Coordinates: walk.Coordinates}
+		properties["kind"] = "walk"
+		properties["distance"] = walk.Distance
+		properties["duration"] = walk.Duration
+		addLine(line, properties)
+		completeRoute.Distance += walk.Distance
+		completeRoute.Duration += walk.Duration
+	}
+
+	// Walk from the origin when planned from coordinates, or else from the
+	// user location if provided, to the first station
+	if journey.WalkStart != nil {
+		addWalk(journey.WalkStart, map[string]interface{}{"to": journey.WalkStart.Station})
+		completeRoute.Path = geometry.Features[0].Geometry
+	} else if user != nil && len(journey.Stations[0].Location.Coordinates) == 2 {
 		firstStation := journey.Stations[0]
-		walkingRoute, err := mapService.GetRoute(
-			userLng, userLat,
-			firstStation.Location.Coordinates[0],
-			firstStation.Location.Coordinates[1],
-		)
-		if err == nil {
-			completeRoute.Path = walkingRoute.Routes[0].Geometry
-			completeRoute.Distance = walkingRoute.Routes[0].Distance
-			completeRoute.Duration = walkingRoute.Routes[0].Duration
-		}
+		station := firstStation.Location.Coordinates
+		straight := utils.HaversineDistance(user.Lat, user.Lng, station[1], station[0]) * 1000
+		distance, duration := h.Planner.EstimateWalk(straight)
+		addWalk(&models.Walk{
+			Station:     firstStation.Name,
+			Distance:    distance,
+			Duration:    duration,
+			Coordinates: [][]float64{{user.Lng, user.Lat}, station},
+		}, map[string]interface{}{"to": firstStation.Name})
+		completeRoute.Path = geometry.Features[0].Geometry
 	}
 
 	// Get driving route between stations
 	var totalDistance, totalDuration float64
-	for i := 0; i < len(journey.Stations)-1; i++ {
-		from := journey.Stations[i]
-		to := journey.Stations[i+1]
-
-		route, err := mapService.GetRoute(
-			from.Location.Coordinates[0], from.Location.Coordinates[1],
-			to.Location.Coordinates[0], to.Location.Coordinates[1],
-		)
-		if err == nil {
-			totalDistance += route.Routes[0].Distance
-			totalDuration += route.Routes[0].Duration
+	for i, route := range h.Roads.Legs(ctx, journey.Stations) {
+		from, to := journey.Stations[i], journey.Stations[i+1]
+		properties := map[string]interface{}{
+			"kind": "ride",
+			"from": from.Name,
+			"to":   to.Name,
+		}
+		if i < len(journey.Legs) {
+			leg := journey.Legs[i]
+			properties["price"] = leg.Price
+			properties["vehicleClass"] = leg.VehicleClass
+			properties["source"] = leg.Source
+			properties["confidence"] = leg.Confidence
 		}
+
+		line := &utils.LineString{Type: "LineString"}
+		if route != nil {
+			totalDistance += route.Distance
+			totalDuration += route.Duration
+			properties["distance"] = route.Distance
+			properties["duration"] = route.Duration
+			properties["approximate"] = route.Approximate
+			if route.Geometry != nil {
+				line = route.Geometry
+			}
+		}
+		if len(line.Coordinates) == 0 {
+			// Without a road route the leg is drawn as a straight line
+			if len(from.Location.Coordinates) != 2 || len(to.Location.Coordinates) != 2 {
+				continue
+			}
+			line.Coordinates = [][]float64{from.Location.Coordinates, to.Location.Coordinates}
+			properties["approximate"] = true
+		}
+		addLine(line, properties)
 	}
 
 	completeRoute.Distance += totalDistance
 	completeRoute.Duration += totalDuration
 
+	if journey.WalkEnd != nil {
+		addWalk(journey.WalkEnd, map[string]interface{}{"from": journey.WalkEnd.Station})
+	}
+
+	for i, station := range journey.Stations {
+		if len(station.Location.Coordinates) != 2 {
+			continue
+		}
+		geometry.Features = append(geometry.Features, utils.NewFeature(
+			utils.NewPoint(station.Location.Coordinates[0], station.Location.Coordinates[1]),
+			map[string]interface{}{
+				"kind":  "station",
+				"id":    station.ID,
+				"name":  station.Name,
+				"index": i,
+			},
+		))
+	}
+	completeRoute.Geometry = geometry
+	if withPolyline {
+		completeRoute.Polyline = utils.EncodePolyline(trip)
+	}
+
 	return c.JSON(completeRoute)
 }
+
+// describeEnd names a journey end for logging
+func describeEnd(name string, point *planner.Point) string {
+	if point != nil {
+		return fmt.Sprintf("(%g, %g)", point.Lat, point.Lng)
+	}
+	return name
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRouteMapUserLocation(t *testing.T) {
+	server := newTestServer(t)
+	query := "/route-map?from=Mexico&to=Piassa&at=2030-03-04T09:00:00Z"
+
+	tests := []struct {
+		name   string
+		user   string
+		status int
+		walk   bool
+	}{
+		{"no user location", "", fiber.StatusOK, false},
+		{"user location", "&user_lat=9.012&user_lng=38.74", fiber.StatusOK, true},
+		{"zero longitude is a location", "&user_lat=9.012&user_lng=0", fiber.StatusOK, true},
+		{"zero latitude is a location", "&user_lat=0&user_lng=38.74", fiber.StatusOK, true},
+		{"half a location", "&user_lat=9.012", fiber.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(t, server, query+tt.user)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d: %v", status, tt.status, body["error"])
+			}
+			if status != fiber.StatusOK {
+				return
+			}
+			features := body["geometry"].(map[string]interface{})["features"].([]interface{})
+			first := features[0].(map[string]interface{})["properties"].(map[string]interface{})
+			if walk := first["kind"] == "walk"; walk != tt.walk {
+				t.Errorf("trip starts with a %v, want a walk: %t", first["kind"], tt.walk)
+			}
+			if walk := body["path"] != nil; walk != tt.walk {
+				t.Errorf("path = %v, want a walk: %t", body["path"], tt.walk)
+			}
+		})
+	}
+}
@@ -2,251 +2,157 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
 	"strings"
-	"taxi-fare-calculator/database"
 	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/planner"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetRoutes(c *fiber.Ctx) error {
-	collection := database.GetCollection("taxi_fare_db", "routes")
+func (h *Handler) GetRoutes(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var routes []models.Route
-	cursor, err := collection.Find(ctx, bson.M{})
+	routes, err := h.Routes.ListRoutes(ctx)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error fetching routes",
 		})
 	}
-	defer cursor.Close(ctx)
-
-	if err = cursor.All(ctx, &routes); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error parsing routes",
-		})
-	}
 
 	return c.JSON(fiber.Map{
 		"routes": routes,
 	})
 }
 
-func GetRoute(c *fiber.Ctx) error {
-	from := c.Query("from")
-	to := c.Query("to")
-
-	if from == "" || to == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Missing from or to parameters",
-		})
-	}
-
-	// Get current time in Addis Ababa
-	location, err := time.LoadLocation("Africa/Addis_Ababa")
+func (h *Handler) GetRoute(c *fiber.Ctx) error {
+	req, err := parseJourneyQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to load timezone",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
-	now := time.Now().In(location)
-	hour := now.Hour()
-	minute := now.Minute()
-	currentTime := float64(hour) + float64(minute)/60.0
 
-	// Check if it's night time (18:30 - 22:30)
-	isNight := currentTime >= 18.5 && currentTime <= 22.5
-
-	collection := database.GetCollection("taxi_fare_db", "routes")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// First try to find a direct route
-	var route models.Route
-	err = collection.FindOne(ctx, bson.M{
-		"from": from,
-		"to":   to,
-	}).Decode(&route)
-
-	if err == nil {
-		// Found a direct route
-		// Apply night fare if applicable
-		price := route.Price
-		if isNight {
-			price = price * 1.4 // 40% increase for night fare
-		}
-		return c.JSON(models.JourneyResponse{
-			Route:      []string{from, to},
-			TotalPrice: price,
-			Legs: []models.RouteLeg{{
-				From:  from,
-				To:    to,
-				Price: price,
-			}},
-			IsNight: isNight,
-		})
-	}
-
-	// If no direct route, find all possible routes
-	var routes []models.Route
-	cursor, err := collection.Find(ctx, bson.M{})
+	journey, err := h.Planner.Plan(ctx, req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error searching for routes",
-		})
+		return planError(c, err)
 	}
-	defer cursor.Close(ctx)
 
-	if err = cursor.All(ctx, &routes); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error parsing routes",
-		})
-	}
+	return c.JSON(newJourneyView(journey))
+}
 
-	// Find the best path using the routes
-	path, totalPrice, legs := findBestPath(routes, from, to)
-	if len(path) == 0 {
+// planError renders a journey planning error
+func planError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, planner.ErrUnknownStation), errors.Is(err, planner.ErrNoStationNearby):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, planner.ErrNoRoute):
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "No route found",
 		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error searching for routes",
+		})
 	}
+}
 
-	// Apply night fare if applicable
-	if isNight {
-		totalPrice = totalPrice * 1.4 // 40% increase for night fare
-		for i := range legs {
-			legs[i].Price = legs[i].Price * 1.4
-		}
+// parseJourneyQuery reads the journey every journey endpoint is asked for:
+// its ends, the time it is priced at and the vehicle classes it may use
+func parseJourneyQuery(c *fiber.Ctx) (planner.Request, error) {
+	req, err := parseEnds(c)
+	if err != nil {
+		return req, err
 	}
-
-	return c.JSON(models.JourneyResponse{
-		Route:      path,
-		TotalPrice: totalPrice,
-		Legs:       legs,
-		IsNight:    isNight,
-	})
+	if req.At, err = parseAt(c); err != nil {
+		return req, err
+	}
+	if req.Modes, err = parseModes(c); err != nil {
+		return req, err
+	}
+	return req, nil
 }
 
-// findBestPath finds the shortest path between two stations using available routes
-func findBestPath(routes []models.Route, from, to string) ([]string, float64, []models.RouteLeg) {
-	// Create a graph representation
-	graph := make(map[string]map[string]float64)
-	for _, route := range routes {
-		if graph[route.From] == nil {
-			graph[route.From] = make(map[string]float64)
-		}
-		graph[route.From][route.To] = route.Price
-
-		// Add reverse direction if it doesn't exist
-		if graph[route.To] == nil {
-			graph[route.To] = make(map[string]float64)
-		}
-		if _, exists := graph[route.To][route.From]; !exists {
-			graph[route.To][route.From] = route.Price
-		}
-
-		// Add connections through intermediate stations
-		if !route.IsDirectRoute && len(route.IntermediateStations) > 0 {
-			prevStation := route.From
-			totalPrice := route.Price / float64(len(route.IntermediateStations)+1)
-
-			for _, station := range route.IntermediateStations {
-				if graph[prevStation] == nil {
-					graph[prevStation] = make(map[string]float64)
-				}
-				graph[prevStation][station] = totalPrice
-
-				if graph[station] == nil {
-					graph[station] = make(map[string]float64)
-				}
-				graph[station][prevStation] = totalPrice
-
-				prevStation = station
-			}
-
-			// Connect last intermediate station to destination
-			if graph[prevStation] == nil {
-				graph[prevStation] = make(map[string]float64)
-			}
-			graph[prevStation][route.To] = totalPrice
-
-			if graph[route.To] == nil {
-				graph[route.To] = make(map[string]float64)
-			}
-			graph[route.To][prevStation] = totalPrice
-		}
+// parseEnds reads where a journey starts and ends: a station name in 'from',
+// or coordinates in 'from_lat' and 'from_lng', and likewise for 'to'
+func parseEnds(c *fiber.Ctx) (planner.Request, error) {
+	req := planner.Request{From: c.Query("from"), To: c.Query("to")}
+	var err error
+	if req.FromPoint, err = parsePoint(c, "from"); err != nil {
+		return req, err
 	}
-
-	// Use Dijkstra's algorithm to find the shortest path
-	distances := make(map[string]float64)
-	previous := make(map[string]string)
-	unvisited := make(map[string]bool)
-
-	// Initialize distances
-	for station := range graph {
-		distances[station] = float64(^uint(0) >> 1) // Max float64
-		unvisited[station] = true
+	if req.ToPoint, err = parsePoint(c, "to"); err != nil {
+		return req, err
 	}
-	distances[from] = 0
-
-	for len(unvisited) > 0 {
-		// Find unvisited node with minimum distance
-		var current string
-		minDist := float64(^uint(0) >> 1)
-		for station := range unvisited {
-			if distances[station] < minDist {
-				current = station
-				minDist = distances[station]
-			}
-		}
-
-		if current == "" || current == to {
-			break
-		}
-
-		delete(unvisited, current)
-
-		// Update distances to neighbors
-		for neighbor, price := range graph[current] {
-			if !unvisited[neighbor] {
-				continue
-			}
-			newDist := distances[current] + price
-			if newDist < distances[neighbor] {
-				distances[neighbor] = newDist
-				previous[neighbor] = current
-			}
-		}
+	if (req.From == "" && req.FromPoint == nil) || (req.To == "" && req.ToPoint == nil) {
+		return req, errors.New("Both 'from' and 'to' parameters are required")
 	}
+	return req, nil
+}
 
-	// Reconstruct path
-	if distances[to] == float64(^uint(0)>>1) {
-		return nil, 0, nil
+// parsePoint reads optional coordinates from the '<prefix>_lat' and
+// '<prefix>_lng' query parameters, or 'lat' and 'lng' without a prefix
+func parsePoint(c *fiber.Ctx, prefix string) (*planner.Point, error) {
+	latKey, lngKey := "lat", "lng"
+	if prefix != "" {
+		latKey, lngKey = prefix+"_lat", prefix+"_lng"
+	}
+	latValue, lngValue := c.Query(latKey), c.Query(lngKey)
+	if latValue == "" && lngValue == "" {
+		return nil, nil
 	}
+	lat, errLat := strconv.ParseFloat(latValue, 64)
+	lng, errLng := strconv.ParseFloat(lngValue, 64)
+	if errLat != nil || errLng != nil || !models.ValidCoordinates(lng, lat) {
+		return nil, fmt.Errorf("'%s' and '%s' must be valid coordinates", latKey, lngKey)
+	}
+	return &planner.Point{Lng: lng, Lat: lat}, nil
+}
 
-	path := []string{to}
-	current := to
-	legs := []models.RouteLeg{}
-	for current != from {
-		prev := previous[current]
-		path = append([]string{prev}, path...)
-		legs = append([]models.RouteLeg{{
-			From:  prev,
-			To:    current,
-			Price: graph[prev][current],
-		}}, legs...)
-		current = prev
+// parseAt reads the optional 'at' query parameter, the time a journey or fare
+// is priced at. A zero time means now.
+func parseAt(c *fiber.Ctx) (time.Time, error) {
+	value := c.Query("at")
+	if value == "" {
+		return time.Time{}, nil
 	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New("'at' must be an RFC 3339 timestamp")
+	}
+	return at, nil
+}
 
-	return path, distances[to], legs
+// parseModes reads the optional comma separated 'modes' query parameter, the
+// vehicle classes a journey may use
+func parseModes(c *fiber.Ctx) ([]string, error) {
+	value := c.Query("modes")
+	if value == "" {
+		return nil, nil
+	}
+	var modes []string
+	for _, mode := range strings.Split(value, ",") {
+		mode = strings.TrimSpace(mode)
+		if !models.ValidVehicleClass(mode) {
+			return nil, fmt.Errorf("'modes' must list vehicle classes from %s", strings.Join(models.VehicleClasses, ", "))
+		}
+		modes = append(modes, mode)
+	}
+	return modes, nil
 }
 
-func AddRoute(c *fiber.Ctx) error {
+func (h *Handler) AddRoute(c *fiber.Ctx) error {
 	route := new(models.Route)
 	if err := c.BodyParser(route); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -255,71 +161,69 @@ func AddRoute(c *fiber.Ctx) error {
 	}
 
 	// Validate route data
-	if route.From == "" || route.To == "" || route.Price <= 0 {
+	if err := route.Validate(); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid route data",
+			"error": err.Error(),
 		})
 	}
 
-	// Validate intermediate stations if not a direct route
-	if !route.IsDirectRoute {
-		if len(route.IntermediateStations) == 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Non-direct route must have intermediate stations",
-			})
-		}
-		// Check for duplicate stations
-		stations := make(map[string]bool)
-		stations[route.From] = true
-		for _, station := range route.IntermediateStations {
-			if station == "" {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid intermediate station",
-				})
-			}
-			if stations[station] {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Duplicate stations in route",
-				})
-			}
-			stations[station] = true
-		}
-		if stations[route.To] {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Duplicate stations in route",
-			})
-		}
-	} else {
-		route.IntermediateStations = nil // Ensure no intermediate stations for direct routes
-	}
-
-	collection := database.GetCollection("taxi_fare_db", "routes")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if err := h.canonicalStops(ctx, route); err != nil {
+		log.Printf("❌ Error resolving route stations: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error creating route",
+		})
+	}
+
 	// Check if route already exists
-	existingRoute := collection.FindOne(ctx, bson.M{
-		"from": route.From,
-		"to":   route.To,
-	})
-	if existingRoute.Err() == nil {
+	if _, err := h.Routes.FindRoute(ctx, route.From, route.To); err == nil {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"error": "Route already exists",
 		})
 	}
 
-	result, err := collection.InsertOne(ctx, route)
+	err := h.Routes.InsertRoute(ctx, route)
+	if errors.Is(err, models.ErrDuplicate) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Route already exists",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error creating route",
 		})
 	}
 
-	route.ID = result.InsertedID.(primitive.ObjectID)
 	return c.Status(fiber.StatusCreated).JSON(route)
 }
 
-func UpdateRoute(c *fiber.Ctx) error {
+// canonicalStops names the stops of a route by the stored station names, so
+// "Megenagna", "Megenagna Station" and an alias are all saved as the same
+// station. Stops that are not stored stations are kept as given.
+func (h *Handler) canonicalStops(ctx context.Context, route *models.Route) error {
+	network, err := h.Graph.Get(ctx)
+	if err != nil {
+		return err
+	}
+	canonical := func(name string) string {
+		name = strings.TrimSpace(name)
+		if station, ok := network.Lookup(name); ok {
+			return station
+		}
+		return name
+	}
+
+	route.From = canonical(route.From)
+	route.To = canonical(route.To)
+	for i, stop := range route.IntermediateStations {
+		route.IntermediateStations[i] = canonical(stop)
+	}
+	return nil
+}
+
+func (h *Handler) UpdateRoute(c *fiber.Ctx) error {
 	id := c.Params("id")
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -335,91 +239,46 @@ func UpdateRoute(c *fiber.Ctx) error {
 		})
 	}
 
-	// Helper function to ensure consistent station names
-	getStationName := func(name string) string {
-		name = strings.TrimSuffix(name, " Station")
-		return name
-	}
-
-	// Clean up station names for consistency
-	route.From = getStationName(route.From)
-	route.To = getStationName(route.To)
-	for i, station := range route.IntermediateStations {
-		route.IntermediateStations[i] = getStationName(station)
-	}
-
 	// Validate route data
-	if route.From == "" || route.To == "" || route.Price <= 0 {
+	if err := route.Validate(); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid route data",
+			"error": err.Error(),
 		})
 	}
 
-	// Validate intermediate stations if not a direct route
-	if !route.IsDirectRoute {
-		if len(route.IntermediateStations) == 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Non-direct route must have intermediate stations",
-			})
-		}
-		// Check for duplicate stations
-		stations := make(map[string]bool)
-		stations[route.From] = true
-		for _, station := range route.IntermediateStations {
-			if station == "" {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid intermediate station",
-				})
-			}
-			if stations[station] {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Duplicate stations in route",
-				})
-			}
-			stations[station] = true
-		}
-		if stations[route.To] {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Duplicate stations in route",
-			})
-		}
-	} else {
-		route.IntermediateStations = nil // Ensure no intermediate stations for direct routes
-	}
-
-	collection := database.GetCollection("taxi_fare_db", "routes")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	update := bson.M{
-		"$set": bson.M{
-			"from":                 route.From,
-			"to":                   route.To,
-			"price":                route.Price,
-			"isDirectRoute":        route.IsDirectRoute,
-			"intermediateStations": route.IntermediateStations,
-		},
-	}
-
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectId}, update)
-	if err != nil {
+	if err := h.canonicalStops(ctx, route); err != nil {
+		log.Printf("❌ Error resolving route stations: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error updating route",
 		})
 	}
 
-	if result.MatchedCount == 0 {
+	err = h.Routes.UpdateRoute(ctx, objectId, route)
+	if errors.Is(err, models.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Route not found",
 		})
 	}
+	if errors.Is(err, models.ErrDuplicate) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Another route already runs between these stations",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error updating route",
+		})
+	}
 
 	return c.JSON(fiber.Map{
 		"message": "Route updated successfully",
 	})
 }
 
-func DeleteRoute(c *fiber.Ctx) error {
+func (h *Handler) DeleteRoute(c *fiber.Ctx) error {
 	id := c.Params("id")
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -428,45 +287,66 @@ func DeleteRoute(c *fiber.Ctx) error {
 		})
 	}
 
-	collection := database.GetCollection("taxi_fare_db", "routes")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	result, err := collection.DeleteOne(ctx, bson.M{"_id": objectId})
+	err = h.Routes.DeleteRoute(ctx, objectId)
+	if errors.Is(err, models.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Route not found",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error deleting route",
 		})
 	}
 
-	if result.DeletedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Route not found",
-		})
-	}
-
 	return c.JSON(fiber.Map{
 		"message": "Route deleted successfully",
 	})
 }
 
-func CalculateJourney(c *fiber.Ctx) error {
-	from := c.Query("from")
-	to := c.Query("to")
-
-	if from == "" || to == "" {
+// GetRoutePrices returns the price history of a route, including deleted routes
+func (h *Handler) GetRoutePrices(c *fiber.Ctx) error {
+	id := c.Params("id")
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Both 'from' and 'to' parameters are required",
+			"error": "Invalid ID format",
 		})
 	}
 
-	collection := database.GetCollection("taxi_fare_db", "routes")
-	journey, err := models.CalculateJourney(from, to, collection)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	records, err := h.History.Records(ctx, objectId)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching route prices",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"prices": records,
+	})
+}
+
+func (h *Handler) CalculateJourney(c *fiber.Ctx) error {
+	req, err := parseJourneyQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return c.JSON(journey)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	journey, err := h.Planner.Plan(ctx, req)
+	if err != nil {
+		return planError(c, err)
+	}
+
+	return c.JSON(newJourneyView(journey))
 }
@@ -2,41 +2,39 @@ package handlers
 
 import (
 	"context"
-	"taxi-fare-calculator/database"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/planner"
+	"taxi-fare-calculator/utils"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetStations(c *fiber.Ctx) error {
-	collection := database.GetCollection("taxi_fare_db", "stations")
+func (h *Handler) GetStations(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var stations []models.Station
-	cursor, err := collection.Find(ctx, bson.M{})
+	stations, err := h.Stations.ListStations(ctx)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error fetching stations",
 		})
 	}
-	defer cursor.Close(ctx)
-
-	if err = cursor.All(ctx, &stations); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error parsing stations",
-		})
-	}
 
 	return c.JSON(fiber.Map{
 		"stations": stations,
 	})
 }
 
-func GetStation(c *fiber.Ctx) error {
+func (h *Handler) GetStation(c *fiber.Ctx) error {
 	id := c.Params("id")
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -45,12 +43,10 @@ func GetStation(c *fiber.Ctx) error {
 		})
 	}
 
-	collection := database.GetCollection("taxi_fare_db", "stations")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var station models.Station
-	err = collection.FindOne(ctx, bson.M{"_id": objectId}).Decode(&station)
+	station, err := h.Stations.GetStation(ctx, objectId)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Station not found",
@@ -60,7 +56,7 @@ func GetStation(c *fiber.Ctx) error {
 	return c.JSON(station)
 }
 
-func AddStation(c *fiber.Ctx) error {
+func (h *Handler) AddStation(c *fiber.Ctx) error {
 	station := new(models.Station)
 
 	if err := c.BodyParser(station); err != nil {
@@ -70,79 +66,48 @@ func AddStation(c *fiber.Ctx) error {
 	}
 
 	// Validate station data
-	if station.Name == "" || len(station.Location.Coordinates) != 2 {
+	if err := station.Validate(); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid station data",
+			"error": err.Error(),
 		})
 	}
 
-	// Set GeoJSON type if not set
-	if station.Location.Type == "" {
-		station.Location.Type = "Point"
-	}
-
-	collection := database.GetCollection("taxi_fare_db", "stations")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	result, err := collection.InsertOne(ctx, station)
+	err := h.Stations.InsertStation(ctx, station)
+	if errors.Is(err, models.ErrDuplicate) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Station already exists",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error creating station",
 		})
 	}
 
-	station.ID = result.InsertedID.(primitive.ObjectID)
 	return c.Status(fiber.StatusCreated).JSON(station)
 }
 
-func FindNearestStation(c *fiber.Ctx) error {
-	lat := c.QueryFloat("lat", 0)
-	lng := c.QueryFloat("lng", 0)
-
-	if lat == 0 || lng == 0 {
+func (h *Handler) FindNearestStation(c *fiber.Ctx) error {
+	point, err := parsePoint(c, "")
+	if err != nil || point == nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid coordinates",
 		})
 	}
 
-	collection := database.GetCollection("taxi_fare_db", "stations")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Find nearest station using geospatial query
-	pipeline := bson.A{
-		bson.D{
-			{Key: "$geoNear", Value: bson.D{
-				{Key: "near", Value: bson.D{
-					{Key: "type", Value: "Point"},
-					{Key: "coordinates", Value: []float64{lng, lat}},
-				}},
-				{Key: "distanceField", Value: "distance"},
-				{Key: "spherical", Value: true},
-			}},
-		},
-		bson.D{{Key: "$limit", Value: 1}},
-	}
-
-	cursor, err := collection.Aggregate(ctx, pipeline)
+	results, err := h.Stations.NearestStations(ctx, point.Lng, point.Lat, 0, 1)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error finding nearest station",
 		})
 	}
-	defer cursor.Close(ctx)
-
-	var results []struct {
-		Station  models.Station `bson:",inline"`
-		Distance float64        `bson:"distance"`
-	}
-
-	if err = cursor.All(ctx, &results); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error parsing results",
-		})
-	}
 
 	if len(results) == 0 {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -156,7 +121,192 @@ func FindNearestStation(c *fiber.Ctx) error {
 	})
 }
 
-func DeleteStation(c *fiber.Ctx) error {
+// Limits of the nearby stations search
+const (
+	defaultNearbyRadius = 1000.0 // meters
+	maxNearbyRadius     = 20000.0
+	defaultNearbyLimit  = 10
+	maxNearbyLimit      = 100
+)
+
+// NearbyStation is a station found near a point
+type NearbyStation struct {
+	Station      models.Station `json:"station"`
+	Distance     float64        `json:"distance_meters"`
+	WalkDistance float64        `json:"walking_distance_meters"`
+	WalkMinutes  float64        `json:"walking_minutes"`
+}
+
+// GetNearbyStations lists the stations within 'radius' meters of lat/lng, or
+// inside a 'bbox' of minLng,minLat,maxLng,maxLat, nearest first. They can be
+// narrowed to stations served by 'modes' or with a ride 'to' a station.
+func (h *Handler) GetNearbyStations(c *fiber.Ctx) error {
+	point, err := parsePoint(c, "")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	box, err := parseBox(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if point == nil && box == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Either 'lat' and 'lng' or 'bbox' is required",
+		})
+	}
+
+	radius := c.QueryFloat("radius", defaultNearbyRadius)
+	if radius < 1 || radius > maxNearbyRadius {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("'radius' must be between 1 and %g meters", maxNearbyRadius),
+		})
+	}
+	limit := c.QueryInt("limit", defaultNearbyLimit)
+	if limit < 1 || limit > maxNearbyLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("'limit' must be between 1 and %d", maxNearbyLimit),
+		})
+	}
+	modes, err := parseModes(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	to := c.Query("to")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var found []models.StationDistance
+	if box != nil {
+		// Distances in a box are measured from the point, or else its center
+		if point == nil {
+			point = &planner.Point{Lng: (box[0] + box[2]) / 2, Lat: (box[1] + box[3]) / 2}
+		}
+		stations, err := h.Stations.StationsWithin(ctx, box[0], box[1], box[2], box[3])
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error finding stations",
+			})
+		}
+		for _, station := range stations {
+			found = append(found, models.StationDistance{
+				Station: station,
+				Distance: utils.HaversineDistance(point.Lat, point.Lng,
+					station.Location.Coordinates[1], station.Location.Coordinates[0]) * 1000,
+			})
+		}
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].Distance < found[j].Distance
+		})
+	} else {
+		found, err = h.Stations.NearestStations(ctx, point.Lng, point.Lat, radius, 0)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error finding stations",
+			})
+		}
+	}
+
+	if to != "" || len(modes) > 0 {
+		found, err = h.Planner.Served(ctx, found, to, modes)
+		if err != nil {
+			return planError(c, err)
+		}
+	}
+	if len(found) > limit {
+		found = found[:limit]
+	}
+
+	nearby := make([]NearbyStation, 0, len(found))
+	for _, result := range found {
+		walkDistance, walkDuration := h.Planner.EstimateWalk(result.Distance)
+		nearby = append(nearby, NearbyStation{
+			Station:      result.Station,
+			Distance:     result.Distance,
+			WalkDistance: walkDistance,
+			WalkMinutes:  math.Round(walkDuration/60*10) / 10,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"stations": nearby,
+		"count":    len(nearby),
+	})
+}
+
+// parseBox reads the optional 'bbox' query parameter, a map viewport given
+// as minLng,minLat,maxLng,maxLat
+func parseBox(c *fiber.Ctx) ([]float64, error) {
+	value := c.Query("bbox")
+	if value == "" {
+		return nil, nil
+	}
+	invalid := errors.New("'bbox' must be minLng,minLat,maxLng,maxLat")
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, invalid
+	}
+	box := make([]float64, 4)
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, invalid
+		}
+		box[i] = f
+	}
+	if !models.ValidCoordinates(box[0], box[1]) || !models.ValidCoordinates(box[2], box[3]) || box[0] > box[2] || box[1] > box[3] {
+		return nil, invalid
+	}
+	return box, nil
+}
+
+// Limits of the station name search
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// SearchStations ranks the stations matching 'q' by name, alias, slug or ID,
+// across scripts and spellings and with typos, best first
+func (h *Handler) SearchStations(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "'q' parameter is required",
+		})
+	}
+	limit := c.QueryInt("limit", defaultSearchLimit)
+	if limit < 1 || limit > maxSearchLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("'limit' must be between 1 and %d", maxSearchLimit),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	results, err := h.Planner.Search(ctx, query, limit)
+	if err != nil {
+		log.Printf("❌ Error searching stations: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error searching stations",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"query":   query,
+		"results": results,
+		"count":   len(results),
+	})
+}
+
+func (h *Handler) DeleteStation(c *fiber.Ctx) error {
 	id := c.Params("id")
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -165,18 +315,23 @@ func DeleteStation(c *fiber.Ctx) error {
 		})
 	}
 
-	collection := database.GetCollection("taxi_fare_db", "stations")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	station, err := h.Stations.GetStation(ctx, objectId)
+	if errors.Is(err, models.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Station not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error deleting station",
+		})
+	}
+
 	// First check if the station is referenced in any routes
-	routesCollection := database.GetCollection("taxi_fare_db", "routes")
-	routeCount, err := routesCollection.CountDocuments(ctx, bson.M{
-		"$or": []bson.M{
-			{"from": id},
-			{"to": id},
-		},
-	})
+	routeCount, err := h.Routes.CountRoutesForStation(ctx, station.Name)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error checking route references",
@@ -189,25 +344,24 @@ func DeleteStation(c *fiber.Ctx) error {
 	}
 
 	// If no routes reference this station, proceed with deletion
-	result, err := collection.DeleteOne(ctx, bson.M{"_id": objectId})
+	err = h.Stations.DeleteStation(ctx, objectId)
+	if errors.Is(err, models.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Station not found",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error deleting station",
 		})
 	}
 
-	if result.DeletedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Station not found",
-		})
-	}
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Station deleted successfully",
 	})
 }
 
-func UpdateStation(c *fiber.Ctx) error {
+func (h *Handler) UpdateStation(c *fiber.Ctx) error {
 	id := c.Params("id")
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -223,42 +377,39 @@ func UpdateStation(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate station data
-	if station.Name == "" || len(station.Location.Coordinates) != 2 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid station data",
-		})
-	}
-
-	// Set GeoJSON type if not set
-	if station.Location.Type == "" {
-		station.Location.Type = "Point"
-	}
-
-	collection := database.GetCollection("taxi_fare_db", "stations")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	update := bson.M{
-		"$set": bson.M{
-			"name":     station.Name,
-			"image":    station.Image,
-			"location": station.Location,
-		},
+	// Keep the slug of a renamed station unless a new one is given
+	if station.Slug == "" {
+		if existing, err := h.Stations.GetStation(ctx, objectId); err == nil {
+			station.Slug = existing.Slug
+		}
 	}
 
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectId}, update)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error updating station",
+	// Validate station data
+	if err := station.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
-	if result.MatchedCount == 0 {
+	err = h.Stations.UpdateStation(ctx, objectId, station)
+	if errors.Is(err, models.ErrNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Station not found",
 		})
 	}
+	if errors.Is(err, models.ErrDuplicate) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Another station already has this name",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error updating station",
+		})
+	}
 
 	return c.JSON(fiber.Map{
 		"message": "Station updated successfully",
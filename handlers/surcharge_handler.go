@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"taxi-fare-calculator/fares"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetSurcharges returns the surcharge rules in effect
+func (h *Handler) GetSurcharges(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	surcharges, err := h.Fares.Surcharges(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching surcharges",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"surcharges": surcharges,
+	})
+}
+
+func (h *Handler) AddSurcharge(c *fiber.Ctx) error {
+	surcharge := new(models.Surcharge)
+	if err := c.BodyParser(surcharge); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot parse JSON",
+		})
+	}
+
+	if err := surcharge.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := h.Fares.AddSurcharge(ctx, surcharge)
+	if errors.Is(err, fares.ErrDuplicateSurcharge) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Error creating surcharge: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error creating surcharge",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(surcharge)
+}
+
+func (h *Handler) UpdateSurcharge(c *fiber.Ctx) error {
+	id := c.Params("id")
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	surcharge := new(models.Surcharge)
+	if err := c.BodyParser(surcharge); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot parse JSON",
+		})
+	}
+
+	if err := surcharge.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = h.Fares.UpdateSurcharge(ctx, objectId, surcharge)
+	if errors.Is(err, models.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Surcharge not found",
+		})
+	}
+	if errors.Is(err, fares.ErrDuplicateSurcharge) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error updating surcharge",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Surcharge updated successfully",
+	})
+}
+
+func (h *Handler) DeleteSurcharge(c *fiber.Ctx) error {
+	id := c.Params("id")
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = h.Surcharges.DeleteSurcharge(ctx, objectId)
+	if errors.Is(err, models.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Surcharge not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error deleting surcharge",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Surcharge deleted successfully",
+	})
+}
+
+func (h *Handler) GetHolidays(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	holidays, err := h.Surcharges.ListHolidays(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching holidays",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"holidays": holidays,
+	})
+}
+
+func (h *Handler) AddHoliday(c *fiber.Ctx) error {
+	holiday := new(models.Holiday)
+	if err := c.BodyParser(holiday); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot parse JSON",
+		})
+	}
+
+	if err := holiday.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.Surcharges.InsertHoliday(ctx, holiday); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error creating holiday",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(holiday)
+}
+
+func (h *Handler) DeleteHoliday(c *fiber.Ctx) error {
+	id := c.Params("id")
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = h.Surcharges.DeleteHoliday(ctx, objectId)
+	if errors.Is(err, models.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Holiday not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error deleting holiday",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Holiday deleted successfully",
+	})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"taxi-fare-calculator/fares"
+	"taxi-fare-calculator/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetTariffs(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tariffs, err := h.Fares.Tariffs(ctx, c.Query("vehicle_class"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching tariffs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"tariffs": tariffs,
+	})
+}
+
+// GetCurrentTariff returns the tariff in force now, or at the time given by 'at'
+func (h *Handler) GetCurrentTariff(c *fiber.Ctx) error {
+	at, err := parseAt(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tariff, err := h.Fares.TariffAt(ctx, c.Query("vehicle_class", models.VehicleMinibus), at)
+	if errors.Is(err, fares.ErrNoTariff) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching tariff",
+		})
+	}
+
+	return c.JSON(tariff)
+}
+
+func (h *Handler) PublishTariff(c *fiber.Ctx) error {
+	tariff := new(models.Tariff)
+	if err := c.BodyParser(tariff); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot parse JSON",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := h.Fares.Publish(ctx, tariff)
+	if errors.Is(err, fares.ErrInvalidTariff) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, fares.ErrOutOfOrder) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Error publishing tariff: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error publishing tariff",
+		})
+	}
+
+	log.Printf("✅ Published %s tariff effective from %s", tariff.VehicleClass, tariff.EffectiveFrom.Format(time.RFC3339))
+	return c.Status(fiber.StatusCreated).JSON(tariff)
+}
@@ -5,10 +5,9 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"taxi-fare-calculator/app"
 	"taxi-fare-calculator/config"
-	"taxi-fare-calculator/database"
 	"taxi-fare-calculator/handlers"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -17,30 +16,28 @@ import (
 
 func main() {
 	// Load configuration
-	log.Printf("🚀 Starting Taxi Fare Calculator API...")
 	cfg := config.LoadConfig()
 
-	// Connect to database with retries
-	maxRetries := 3
-	var err error
-	for i := 0; i < maxRetries; i++ {
-		err = database.ConnectDB(cfg.MongoURI)
-		if err == nil {
-			break
-		}
-		log.Printf("❌ Failed to connect to MongoDB (attempt %d/%d): %v", i+1, maxRetries, err)
-		if i < maxRetries-1 {
-			log.Printf("Retrying in 5 seconds...")
-			time.Sleep(5 * time.Second)
+	// Run a CLI subcommand instead of the server if one is given
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("❌ %v", err)
 		}
+		return
 	}
+
+	log.Printf("🚀 Starting Taxi Fare Calculator API...")
+
+	// Wire up storage and services
+	application, err := app.New(cfg)
 	if err != nil {
-		log.Fatalf("❌ Failed to initialize database after %d attempts: %v", maxRetries, err)
+		log.Fatalf("❌ %v", err)
 	}
-	defer database.DisconnectDB()
+	defer application.Close()
+	h := handlers.New(application)
 
 	// Initialize Fiber
-	app := fiber.New(fiber.Config{
+	server := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
@@ -49,8 +46,8 @@ func main() {
 	})
 
 	// Middleware
-	app.Use(logger.New())
-	app.Use(cors.New(cors.Config{
+	server.Use(logger.New())
+	server.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:3001,https://redat.vercel.app",
 		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
 		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
@@ -59,37 +56,70 @@ func main() {
 	}))
 
 	// Station Routes
-	app.Get("/stations", handlers.GetStations)
-	app.Get("/stations/:id", handlers.GetStation)
-	app.Post("/stations", handlers.AddStation)
-	app.Delete("/stations/:id", handlers.DeleteStation)
-	app.Put("/stations/:id", handlers.UpdateStation)
+	server.Get("/stations", h.GetStations)
+	server.Get("/stations/nearby", h.GetNearbyStations)
+	server.Get("/stations/search", h.SearchStations)
+	server.Get("/stations/:id", h.GetStation)
+	server.Post("/stations", h.AddStation)
+	server.Delete("/stations/:id", h.DeleteStation)
+	server.Put("/stations/:id", h.UpdateStation)
 
 	// Route Routes
-	app.Get("/routes", handlers.GetRoutes)
-	app.Get("/route", handlers.GetRoute)
-	app.Post("/routes", handlers.AddRoute)
-	app.Put("/routes/:id", handlers.UpdateRoute)
-	app.Delete("/routes/:id", handlers.DeleteRoute)
-	app.Get("/journey", handlers.CalculateJourney)
-	app.Get("/nearest-station", handlers.FindNearestStation)
-	app.Get("/route-map", handlers.GetRouteWithMap)
-	app.Get("/places", handlers.GetPlaces)
+	server.Get("/routes", h.GetRoutes)
+	server.Get("/route", h.GetRoute)
+	server.Post("/routes", h.AddRoute)
+	server.Put("/routes/:id", h.UpdateRoute)
+	server.Delete("/routes/:id", h.DeleteRoute)
+	server.Get("/routes/:id/prices", h.GetRoutePrices)
+	server.Get("/journey", h.CalculateJourney)
+	server.Get("/journeys", h.GetJourneys)
+	server.Get("/nearest-station", h.FindNearestStation)
+	server.Get("/route-map", h.GetRouteWithMap)
+	server.Get("/places", h.GetPlaces)
+
+	// Dataset export
+	server.Get("/export", h.ExportDataset)
+	server.Get("/export/gtfs", h.ExportGTFS)
+
+	// Bulk import, which may rewrite every station and route
+	server.Post("/import/gtfs", h.RequireAdmin, h.ImportGTFS)
+
+	// Tariffs
+	server.Get("/tariffs", h.GetTariffs)
+	server.Get("/tariffs/current", h.GetCurrentTariff)
+	server.Post("/tariffs", h.RequireAdmin, h.PublishTariff)
+
+	// Surcharges
+	server.Get("/surcharges", h.GetSurcharges)
+	server.Post("/surcharges", h.RequireAdmin, h.AddSurcharge)
+	server.Put("/surcharges/:id", h.RequireAdmin, h.UpdateSurcharge)
+	server.Delete("/surcharges/:id", h.RequireAdmin, h.DeleteSurcharge)
+	server.Get("/holidays", h.GetHolidays)
+	server.Post("/holidays", h.RequireAdmin, h.AddHoliday)
+	server.Delete("/holidays/:id", h.RequireAdmin, h.DeleteHoliday)
+
+	// Calendar and fare events
+	server.Get("/calendar", h.GetCalendarDay)
+	server.Get("/calendar/holidays", h.GetEthiopianHolidays)
+	server.Get("/events", h.GetFareEvents)
+	server.Post("/events", h.RequireAdmin, h.AddFareEvent)
+	server.Delete("/events/:id", h.RequireAdmin, h.DeleteFareEvent)
 
 	// Contribution endpoint
-	app.Post("/api/contribute", handlers.HandleContribution)
+	server.Post("/api/contribute", h.HandleContribution)
 
 	// Health check
-	app.Get("/health", func(c *fiber.Ctx) error {
+	server.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"status":   "ok",
 			"database": "connected",
+			"storage":  cfg.Storage,
 		})
 	})
 
 	// Add static file serving
-	app.Static("/static", "./static")
-	app.Get("/", handlers.ServeMapUI)
+	server.Static("/static", "./static")
+	server.Get("/", handlers.ServeMapUI)
 
 	// Graceful shutdown
 	c := make(chan os.Signal, 1)
@@ -97,12 +127,12 @@ func main() {
 	go func() {
 		<-c
 		log.Printf("🛑 Gracefully shutting down...")
-		_ = app.Shutdown()
+		_ = server.Shutdown()
 	}()
 
 	// Start server
 	log.Printf("🌍 Server starting on port %s", cfg.Port)
-	if err := app.Listen(":" + cfg.Port); err != nil {
+	if err := server.Listen(":" + cfg.Port); err != nil {
 		log.Fatalf("❌ Server error: %v", err)
 	}
 }
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"taxi-fare-calculator/calendar"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FareEvent is a dated event affecting fares, such as a holiday fare spike or
+// a road closure. Its dates are stored in the Gregorian calendar and may be
+// given in the Ethiopian calendar when it is registered.
+type FareEvent struct {
+	ID   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name string             `json:"name" bson:"name"`
+	// Start and End are inclusive dates as YYYY-MM-DD
+	Start string `json:"start" bson:"start"`
+	End   string `json:"end" bson:"end"`
+	// Calendar is "ethiopian" when Start and End were given as Ethiopian dates;
+	// they are converted on validation
+	Calendar string `json:"calendar,omitempty" bson:"-"`
+	// Multiplier scales all fares during the event
+	Multiplier float64 `json:"multiplier" bson:"multiplier"`
+	// SuspendedRoutes cannot be taken during the event
+	SuspendedRoutes []primitive.ObjectID `json:"suspended_routes,omitempty" bson:"suspended_routes,omitempty"`
+	// Notice is shown to riders
+	Notice string `json:"notice,omitempty" bson:"notice,omitempty"`
+
+	// The event dates in the Ethiopian calendar, derived from Start and End
+	StartEthiopian string `json:"start_ethiopian,omitempty" bson:"-"`
+	EndEthiopian   string `json:"end_ethiopian,omitempty" bson:"-"`
+}
+
+// CalendarEthiopian marks fare event dates given in the Ethiopian calendar
+const CalendarEthiopian = "ethiopian"
+
+// Validate checks the event, converting Ethiopian dates to Gregorian
+func (e *FareEvent) Validate() error {
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return errors.New("Event name is required")
+	}
+	if e.End == "" {
+		e.End = e.Start
+	}
+
+	switch e.Calendar {
+	case CalendarEthiopian:
+		start, err := calendar.Parse(e.Start)
+		if err != nil {
+			return errors.New("Start must be an Ethiopian date as YYYY-MM-DD")
+		}
+		end, err := calendar.Parse(e.End)
+		if err != nil {
+			return errors.New("End must be an Ethiopian date as YYYY-MM-DD")
+		}
+		e.Start = start.Gregorian().Format("2006-01-02")
+		e.End = end.Gregorian().Format("2006-01-02")
+		e.Calendar = ""
+	case "", "gregorian":
+		if _, err := time.Parse("2006-01-02", e.Start); err != nil {
+			return errors.New("Start must be a date as YYYY-MM-DD")
+		}
+		if _, err := time.Parse("2006-01-02", e.End); err != nil {
+			return errors.New("End must be a date as YYYY-MM-DD")
+		}
+		e.Calendar = ""
+	default:
+		return errors.New("Calendar must be ethiopian or gregorian")
+	}
+	if e.End < e.Start {
+		return errors.New("Event ends before it starts")
+	}
+
+	if e.Multiplier < 0 {
+		return errors.New("Invalid event multiplier")
+	}
+	if e.Multiplier == 0 {
+		e.Multiplier = 1
+	}
+	if e.Multiplier == 1 && len(e.SuspendedRoutes) == 0 && e.Notice == "" {
+		return errors.New("Event needs a multiplier, suspended routes or a notice")
+	}
+	return nil
+}
+
+// SetEthiopianDates fills in the Ethiopian calendar dates of the event
+func (e *FareEvent) SetEthiopianDates() {
+	if start, err := time.Parse("2006-01-02", e.Start); err == nil {
+		e.StartEthiopian = calendar.FromGregorian(start).String()
+	}
+	if end, err := time.Parse("2006-01-02", e.End); err == nil {
+		e.EndEthiopian = calendar.FromGregorian(end).String()
+	}
+}
+
+// Suspends reports whether the event suspends a route
+func (e *FareEvent) Suspends(routeID primitive.ObjectID) bool {
+	for _, id := range e.SuspendedRoutes {
+		if id == routeID {
+			return true
+		}
+	}
+	return false
+}
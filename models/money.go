@@ -0,0 +1,141 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Money is an amount in santim, 1/100 of a Birr. It is read and written as a
+// number of Birr in JSON and BSON so stored prices and the API keep their shape.
+type Money int64
+
+// Birr is one Birr in santim
+const Birr Money = 100
+
+// FromBirr converts an amount in Birr to the nearest santim
+func FromBirr(birr float64) Money {
+	return Money(math.Round(birr * float64(Birr)))
+}
+
+// Birr returns the amount in Birr
+func (m Money) Birr() float64 {
+	return float64(m) / float64(Birr)
+}
+
+// Mul scales the amount, rounding to the nearest santim
+func (m Money) Mul(factor float64) Money {
+	return Money(math.Round(float64(m) * factor))
+}
+
+// Round rounds the amount to the nearest multiple of step, halves rounding up
+func (m Money) Round(step Money) Money {
+	if step <= 1 {
+		return m
+	}
+	return Money(math.Round(float64(m)/float64(step))) * step
+}
+
+// Split divides the amount into n parts differing by at most a santim that
+// add up to the amount exactly
+func (m Money) Split(n int) []Money {
+	if n <= 0 {
+		return nil
+	}
+	weights := make([]Money, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return m.Allocate(weights)
+}
+
+// Allocate divides the amount in proportion to weights, giving leftover santim
+// to the parts with the largest remainders, so the parts add up to the amount
+// exactly. Without any positive weight the amount is split evenly.
+func (m Money) Allocate(weights []Money) []Money {
+	parts := make([]Money, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	var sum Money
+	for _, weight := range weights {
+		if weight > 0 {
+			sum += weight
+		}
+	}
+	if sum == 0 {
+		return m.Split(len(weights))
+	}
+
+	remainders := make([]float64, len(weights))
+	var allocated Money
+	for i, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		share := float64(m) * float64(weight) / float64(sum)
+		parts[i] = Money(math.Floor(share))
+		remainders[i] = share - math.Floor(share)
+		allocated += parts[i]
+	}
+	for left := m - allocated; left > 0; left-- {
+		best := -1
+		for i := range remainders {
+			if weights[i] > 0 && (best < 0 || remainders[i] > remainders[best]) {
+				best = i
+			}
+		}
+		parts[best]++
+		remainders[best] = -1
+	}
+	return parts
+}
+
+// String formats the amount in Birr, e.g. "12.5"
+func (m Money) String() string {
+	return strconv.FormatFloat(m.Birr(), 'f', -1, 64)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a number of Birr, also when quoted
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	var birr float64
+	if err := json.Unmarshal(data, &birr); err != nil {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+	*m = FromBirr(birr)
+	return nil
+}
+
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.TypeDouble, bsoncore.AppendDouble(nil, m.Birr()), nil
+}
+
+// UnmarshalBSONValue accepts Birr stored as any BSON number
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeDouble:
+		*m = FromBirr(value.Double())
+	case bson.TypeInt32:
+		*m = Money(value.Int32()) * Birr
+	case bson.TypeInt64:
+		*m = Money(value.Int64()) * Birr
+	case bson.TypeNull, bson.TypeUndefined:
+		*m = 0
+	default:
+		return fmt.Errorf("cannot decode %s as an amount", t)
+	}
+	return nil
+}
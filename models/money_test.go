@@ -0,0 +1,63 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoneySplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Money
+		n      int
+		want   []Money
+	}{
+		{"even", 900, 3, []Money{300, 300, 300}},
+		{"one santim left goes to the first part", 1000, 3, []Money{334, 333, 333}},
+		{"two santim left go to the first parts", 200, 3, []Money{67, 67, 66}},
+		{"less than a santim each", 2, 3, []Money{1, 1, 0}},
+		{"no parts", 1000, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.amount.Split(tt.n)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s.Split(%d) = %v, want %v", tt.amount, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  Money
+		weights []Money
+		want    []Money
+	}{
+		{"proportional", 1000, []Money{1, 3}, []Money{250, 750}},
+		{"leftover to the largest remainder", 1000, []Money{1, 2}, []Money{333, 667}},
+		{"leftovers to the largest remainders", 1000, []Money{3, 3, 1}, []Money{429, 428, 143}},
+		{"zero and negative weights get nothing", 101, []Money{-1, 1, 0, 1}, []Money{0, 51, 0, 50}},
+		{"no positive weight splits evenly", 500, []Money{0, 0}, []Money{250, 250}},
+		{"no weights", 500, nil, []Money{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.amount.Allocate(tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("%s.Allocate(%v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+			if len(got) == 0 {
+				return
+			}
+			var sum Money
+			for _, part := range got {
+				sum += part
+			}
+			if sum != tt.amount {
+				t.Errorf("parts add up to %s, want %s", sum, tt.amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PriceRecord is one version of a route's price and stops, valid from
+// ValidFrom until ValidUntil (open-ended while current). A zero ValidFrom means
+// the version predates price history.
+type PriceRecord struct {
+	ID                   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RouteID              primitive.ObjectID `json:"route_id" bson:"route_id"`
+	From                 string             `json:"from" bson:"from"`
+	To                   string             `json:"to" bson:"to"`
+	Price                Money              `json:"price" bson:"price"`
+	IsDirectRoute        bool               `json:"is_direct_route" bson:"is_direct_route"`
+	IntermediateStations []string           `json:"intermediate_stations,omitempty" bson:"intermediate_stations,omitempty"`
+	Services             []Service          `json:"services,omitempty" bson:"services,omitempty"`
+	Source               string             `json:"source" bson:"source"`
+	ValidFrom            time.Time          `json:"valid_from" bson:"valid_from"`
+	ValidUntil           *time.Time         `json:"valid_until,omitempty" bson:"valid_until,omitempty"`
+}
+
+// ValidAt reports whether the record was in force at the given time
+func (p *PriceRecord) ValidAt(at time.Time) bool {
+	return !p.ValidFrom.After(at) && (p.ValidUntil == nil || p.ValidUntil.After(at))
+}
+
+// Route returns the route as it was while the record was in force
+func (p *PriceRecord) Route() Route {
+	return Route{
+		ID:                   p.RouteID,
+		From:                 p.From,
+		To:                   p.To,
+		Price:                p.Price,
+		IsDirectRoute:        p.IsDirectRoute,
+		IntermediateStations: append([]string(nil), p.IntermediateStations...),
+		PriceSource:          p.Source,
+		Services:             append([]Service(nil), p.Services...),
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RoadLeg is a road route between two stations kept so the router is not
+// asked again. Hash identifies the station coordinates it was found for.
+type RoadLeg struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	From        primitive.ObjectID `json:"from" bson:"from"`
+	To          primitive.ObjectID `json:"to" bson:"to"`
+	Hash        string             `json:"hash" bson:"hash"`
+	Distance    float64            `json:"distance" bson:"distance"` // meters
+	Duration    float64            `json:"duration" bson:"duration"` // seconds
+	Coordinates [][]float64        `json:"coordinates" bson:"coordinates"`
+	FetchedAt   time.Time          `json:"fetched_at" bson:"fetched_at"`
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"errors"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -8,20 +11,86 @@ type Route struct {
 	ID                   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	From                 string             `json:"from" bson:"from"`
 	To                   string             `json:"to" bson:"to"`
-	Price                float64            `json:"price" bson:"price"`
+	Price                Money              `json:"price" bson:"price"`
 	IsDirectRoute        bool               `json:"isDirectRoute" bson:"isDirectRoute"`
 	IntermediateStations []string           `json:"intermediateStations,omitempty" bson:"intermediateStations,omitempty"`
+	// PriceSource says where the price comes from, e.g. a survey or a Transport Bureau circular
+	PriceSource string `json:"priceSource,omitempty" bson:"priceSource,omitempty"`
+	// Services lists vehicle classes other than the minibus, or the minibus
+	// when it differs from Price, with their fares and frequencies
+	Services []Service `json:"services,omitempty" bson:"services,omitempty"`
 }
 
-type JourneyResponse struct {
-	Route      []string   `json:"route"`
-	TotalPrice float64    `json:"totalPrice"`
-	Legs       []RouteLeg `json:"legs"`
-	IsNight    bool       `json:"isNight"`
+type RouteLeg struct {
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Price        Money  `json:"price"`
+	VehicleClass string `json:"vehicleClass,omitempty"`
+	// Source says how the price was obtained, one of the LegSource values
+	Source string `json:"source,omitempty"`
+	// VerifiedAt is when the route's current price was recorded, if known
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
+	// Confidence is how far the price can be trusted, from 0 to 1
+	Confidence float64 `json:"confidence"`
 }
 
-type RouteLeg struct {
-	From  string  `json:"from"`
-	To    string  `json:"to"`
-	Price float64 `json:"price"`
+// Ways a leg price is obtained
+const (
+	// LegSourceRoute is the stored price of a route between adjacent stops
+	LegSourceRoute = "verified_route"
+	// LegSourceSplit is an even share of the price of a route with intermediate stops
+	LegSourceSplit = "derived_split"
+	// LegSourceEstimate is a fare calculated from the distance under a tariff
+	LegSourceEstimate = "distance_estimate"
+)
+
+// PriceSourceGTFSEstimate is the price source of imported routes whose fare
+// the feed did not give and was estimated from the distance
+const PriceSourceGTFSEstimate = "gtfs distance estimate"
+
+// Ride is one vehicle taken during a journey, from boarding to alighting
+type Ride struct {
+	RouteID          string   `json:"routeId"`
+	VehicleClass     string   `json:"vehicleClass"`
+	FrequencyMinutes int      `json:"frequencyMinutes,omitempty"`
+	From             string   `json:"from"`
+	To               string   `json:"to"`
+	Stops            []string `json:"stops"`
+	Price            Money    `json:"price"`
+}
+
+// Validate checks the route data and clears intermediate stations on direct routes
+func (r *Route) Validate() error {
+	if r.From == "" || r.To == "" || r.Price < 0 || (r.Price == 0 && len(r.Services) == 0) {
+		return errors.New("Invalid route data")
+	}
+	if err := validateServices(r.Services); err != nil {
+		return err
+	}
+
+	if r.IsDirectRoute {
+		r.IntermediateStations = nil // Ensure no intermediate stations for direct routes
+		return nil
+	}
+
+	if len(r.IntermediateStations) == 0 {
+		return errors.New("Non-direct route must have intermediate stations")
+	}
+
+	// Check for duplicate stations
+	stations := make(map[string]bool)
+	stations[r.From] = true
+	for _, station := range r.IntermediateStations {
+		if station == "" {
+			return errors.New("Invalid intermediate station")
+		}
+		if stations[station] {
+			return errors.New("Duplicate stations in route")
+		}
+		stations[station] = true
+	}
+	if stations[r.To] {
+		return errors.New("Duplicate stations in route")
+	}
+	return nil
 }
@@ -1,11 +1,11 @@
 package models
 
 import (
-	"context"
+	"errors"
+	"strings"
+	"taxi-fare-calculator/utils"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type Location struct {
@@ -14,19 +14,68 @@ type Location struct {
 }
 
 type Station struct {
-	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name            string             `json:"name" bson:"name"`
-	Image           string             `json:"image" bson:"image,omitempty"`
-	Location        Location           `json:"location" bson:"location"`
-	ConnectedRoutes []string           `json:"connected_routes" bson:"connected_routes"`
+	ID   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name string             `json:"name" bson:"name"`
+	// Slug is the canonical ID of the station, derived from its name when it
+	// is created and kept when the station is renamed
+	Slug string `json:"slug,omitempty" bson:"slug,omitempty"`
+	// Aliases are other names riders know the station by, in any language
+	Aliases         []Alias  `json:"aliases,omitempty" bson:"aliases,omitempty"`
+	Image           string   `json:"image" bson:"image,omitempty"`
+	Location        Location `json:"location" bson:"location"`
+	ConnectedRoutes []string `json:"connected_routes" bson:"connected_routes"`
 }
 
-func (s *Station) CreateGeospatialIndex(collection *mongo.Collection) error {
-	index := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "location", Value: "2dsphere"},
-		},
+// Alias is another name of a station, such as its Amharic name
+type Alias struct {
+	Name string `json:"name" bson:"name"`
+	// Lang is the language of the name as an ISO 639-1 code, e.g. "am"
+	Lang string `json:"lang,omitempty" bson:"lang,omitempty"`
+}
+
+// Validate checks the station data and defaults the GeoJSON type and slug
+func (s *Station) Validate() error {
+	if s.Name == "" || len(s.Location.Coordinates) != 2 {
+		return errors.New("Invalid station data")
+	}
+	if !ValidCoordinates(s.Location.Coordinates[0], s.Location.Coordinates[1]) {
+		return errors.New("Invalid station coordinates")
+	}
+	if s.Slug != "" && s.Slug != utils.Slugify(s.Slug) {
+		return errors.New("Invalid station slug, use lower-case letters, digits and hyphens")
+	}
+	for i := range s.Aliases {
+		s.Aliases[i].Name = strings.TrimSpace(s.Aliases[i].Name)
+		if s.Aliases[i].Name == "" {
+			return errors.New("Invalid station alias")
+		}
+	}
+
+	// Set GeoJSON type if not set
+	if s.Location.Type == "" {
+		s.Location.Type = "Point"
+	}
+	if s.Slug == "" {
+		s.Slug = StationSlug(s.Name)
 	}
-	_, err := collection.Indexes().CreateOne(context.Background(), index)
-	return err
+	if s.Slug == "" {
+		return errors.New("Invalid station name")
+	}
+	return nil
+}
+
+// DisplayName is the station name without the " Station" suffix
+func (s *Station) DisplayName() string {
+	return strings.TrimSuffix(s.Name, " Station")
+}
+
+// StationSlug derives the canonical ID of a station from its name
+func StationSlug(name string) string {
+	station := Station{Name: strings.TrimSpace(name)}
+	return utils.Slugify(station.DisplayName())
+}
+
+// ValidCoordinates reports whether a longitude and latitude are within range
+func ValidCoordinates(lng, lat float64) bool {
+	return lng >= -180 && lng <= 180 && lat >= -90 && lat <= 90
 }
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotFound is returned by stores when the requested document does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicate is returned by stores when a write would duplicate a unique
+// key, such as a station name, a route's from/to pair or a surcharge name
+var ErrDuplicate = errors.New("duplicate")
+
+// StationDistance is a station together with its distance in meters from a query point
+type StationDistance struct {
+	Station  Station `json:"station" bson:",inline"`
+	Distance float64 `json:"distance_meters" bson:"distance"`
+}
+
+// StationStore is the persistence interface for stations
+type StationStore interface {
+	ListStations(ctx context.Context) ([]Station, error)
+	GetStation(ctx context.Context, id primitive.ObjectID) (*Station, error)
+	GetStationByName(ctx context.Context, name string) (*Station, error)
+	InsertStation(ctx context.Context, station *Station) error
+	UpdateStation(ctx context.Context, id primitive.ObjectID, station *Station) error
+	// ReplaceStation overwrites every field of the station with the same ID
+	ReplaceStation(ctx context.Context, station *Station) error
+	DeleteStation(ctx context.Context, id primitive.ObjectID) error
+	// NearestStations returns the stations within maxDistance meters of a point
+	// (any distance if zero), nearest first, at most limit of them (all if zero)
+	NearestStations(ctx context.Context, lng, lat, maxDistance float64, limit int) ([]StationDistance, error)
+	// StationsWithin returns the stations inside a longitude/latitude box
+	StationsWithin(ctx context.Context, minLng, minLat, maxLng, maxLat float64) ([]Station, error)
+}
+
+// RouteStore is the persistence interface for routes
+type RouteStore interface {
+	ListRoutes(ctx context.Context) ([]Route, error)
+	GetRoute(ctx context.Context, id primitive.ObjectID) (*Route, error)
+	// FindRoute returns the route going exactly from -> to
+	FindRoute(ctx context.Context, from, to string) (*Route, error)
+	InsertRoute(ctx context.Context, route *Route) error
+	UpdateRoute(ctx context.Context, id primitive.ObjectID, route *Route) error
+	DeleteRoute(ctx context.Context, id primitive.ObjectID) error
+	// CountRoutesForStation counts routes starting or ending at the named station
+	CountRoutesForStation(ctx context.Context, name string) (int64, error)
+}
+
+// TariffStore is the persistence interface for published tariffs
+type TariffStore interface {
+	// ListTariffs returns the tariffs of a vehicle class (all classes if empty), oldest first
+	ListTariffs(ctx context.Context, vehicleClass string) ([]Tariff, error)
+	// TariffAt returns the tariff of a vehicle class in force at the given time
+	TariffAt(ctx context.Context, vehicleClass string, at time.Time) (*Tariff, error)
+	InsertTariff(ctx context.Context, tariff *Tariff) error
+}
+
+// PriceHistoryStore is the persistence interface for route price versions
+type PriceHistoryStore interface {
+	// ListPriceRecords returns the versions of a route (all routes if zero), oldest first
+	ListPriceRecords(ctx context.Context, routeID primitive.ObjectID) ([]PriceRecord, error)
+	// PriceRecordsAt returns the versions of all routes in force at the given time
+	PriceRecordsAt(ctx context.Context, at time.Time) ([]PriceRecord, error)
+	// RecordedRouteIDs returns the IDs of the routes that have any versions
+	RecordedRouteIDs(ctx context.Context) ([]primitive.ObjectID, error)
+	InsertPriceRecord(ctx context.Context, record *PriceRecord) error
+	// ClosePriceRecords ends the open versions of a route at the given time
+	ClosePriceRecords(ctx context.Context, routeID primitive.ObjectID, until time.Time) error
+}
+
+// SurchargeStore is the persistence interface for surcharge rules and holidays
+type SurchargeStore interface {
+	ListSurcharges(ctx context.Context) ([]Surcharge, error)
+	GetSurcharge(ctx context.Context, id primitive.ObjectID) (*Surcharge, error)
+	InsertSurcharge(ctx context.Context, surcharge *Surcharge) error
+	UpdateSurcharge(ctx context.Context, id primitive.ObjectID, surcharge *Surcharge) error
+	DeleteSurcharge(ctx context.Context, id primitive.ObjectID) error
+
+	// ListHolidays returns the holidays ordered by date
+	ListHolidays(ctx context.Context) ([]Holiday, error)
+	InsertHoliday(ctx context.Context, holiday *Holiday) error
+	DeleteHoliday(ctx context.Context, id primitive.ObjectID) error
+}
+
+// FareEventStore is the persistence interface for fare events
+type FareEventStore interface {
+	// ListFareEvents returns the events overlapping the dates from and to
+	// (YYYY-MM-DD, inclusive; empty for unbounded), ordered by start date
+	ListFareEvents(ctx context.Context, from, to string) ([]FareEvent, error)
+	InsertFareEvent(ctx context.Context, event *FareEvent) error
+	DeleteFareEvent(ctx context.Context, id primitive.ObjectID) error
+}
+
+// RoadLegStore is the persistence interface for road routes cached between stations
+type RoadLegStore interface {
+	// GetRoadLeg returns the cached route from one station to another
+	GetRoadLeg(ctx context.Context, from, to primitive.ObjectID) (*RoadLeg, error)
+	// SaveRoadLeg stores the route, replacing any cached between the same stations
+	SaveRoadLeg(ctx context.Context, leg *RoadLeg) error
+	// DeleteRoadLegs removes the cached routes from or to a station
+	DeleteRoadLegs(ctx context.Context, station primitive.ObjectID) error
+}
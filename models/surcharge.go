@@ -0,0 +1,226 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SurchargeNight names the night surcharge; journeys it applies to are reported as night journeys
+const SurchargeNight = "night"
+
+// Holiday handling of a surcharge rule
+const (
+	HolidaysAny    = ""       // holidays are treated like any other day
+	HolidaysOnly   = "only"   // the rule applies only on holidays
+	HolidaysExcept = "except" // the rule never applies on holidays
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Surcharge is a named fare rule applied to rides starting within a daily
+// time window. Times are local to Addis Ababa; a window whose end is before its
+// start runs past midnight and belongs to the day it started on.
+type Surcharge struct {
+	ID   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name string             `json:"name" bson:"name"`
+	// Days lists the days of the week as mon, tue, ...; empty means every day
+	Days     []string `json:"days,omitempty" bson:"days,omitempty"`
+	Start    string   `json:"start,omitempty" bson:"start,omitempty"` // HH:MM, inclusive
+	End      string   `json:"end,omitempty" bson:"end,omitempty"`     // HH:MM, inclusive
+	Holidays string   `json:"holidays,omitempty" bson:"holidays,omitempty"`
+	// Multiplier scales ride prices and AddOn is added per ride
+	Multiplier float64             `json:"multiplier" bson:"multiplier"`
+	AddOn      Money               `json:"add_on" bson:"add_on"`
+	Overrides  []SurchargeOverride `json:"overrides,omitempty" bson:"overrides,omitempty"`
+	Disabled   bool                `json:"disabled" bson:"disabled"`
+	// EffectiveFrom and EffectiveUntil bound when the rule is in force, so
+	// journeys priced for a past date get the rules of that date; unset
+	// means unbounded
+	EffectiveFrom  *time.Time `json:"effective_from,omitempty" bson:"effective_from,omitempty"`
+	EffectiveUntil *time.Time `json:"effective_until,omitempty" bson:"effective_until,omitempty"`
+}
+
+// SurchargeOverride replaces a rule's effect for rides on one route;
+// a multiplier of 1 without add-on exempts the route
+type SurchargeOverride struct {
+	RouteID    primitive.ObjectID `json:"route_id" bson:"route_id"`
+	Multiplier float64            `json:"multiplier" bson:"multiplier"`
+	AddOn      Money              `json:"add_on" bson:"add_on"`
+}
+
+// Holiday is a date on which holiday surcharge rules apply
+type Holiday struct {
+	ID   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Date string             `json:"date" bson:"date"` // YYYY-MM-DD
+	Name string             `json:"name" bson:"name"`
+}
+
+// DefaultSurcharges are the rules a new database is seeded with
+func DefaultSurcharges() []Surcharge {
+	return []Surcharge{{
+		Name:       SurchargeNight,
+		Start:      "18:30",
+		End:        "22:30",
+		Multiplier: 1.4,
+	}}
+}
+
+// Validate checks the rule and fills in defaults
+func (s *Surcharge) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return errors.New("Surcharge name is required")
+	}
+
+	for i, day := range s.Days {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if len(day) > 3 {
+			day = day[:3]
+		}
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("Invalid day %q", s.Days[i])
+		}
+		s.Days[i] = day
+	}
+
+	if (s.Start == "") != (s.End == "") {
+		return errors.New("Surcharge needs both start and end times")
+	}
+	if s.Start != "" {
+		if _, err := clockMinutes(s.Start); err != nil {
+			return errors.New("Start must be a time as HH:MM")
+		}
+		if _, err := clockMinutes(s.End); err != nil {
+			return errors.New("End must be a time as HH:MM")
+		}
+	}
+
+	switch s.Holidays {
+	case HolidaysAny, HolidaysOnly, HolidaysExcept:
+	default:
+		return errors.New("Holidays must be one of only, except")
+	}
+
+	if s.EffectiveFrom != nil && s.EffectiveUntil != nil && !s.EffectiveUntil.After(*s.EffectiveFrom) {
+		return errors.New("Surcharge must stop being effective after it takes effect")
+	}
+
+	if s.Multiplier < 0 || s.AddOn < 0 {
+		return errors.New("Invalid surcharge amount")
+	}
+	if s.Multiplier == 0 {
+		s.Multiplier = 1
+	}
+	for i := range s.Overrides {
+		override := &s.Overrides[i]
+		if override.RouteID.IsZero() {
+			return errors.New("Surcharge override needs a route_id")
+		}
+		if override.Multiplier < 0 || override.AddOn < 0 {
+			return errors.New("Invalid surcharge override amount")
+		}
+		if override.Multiplier == 0 {
+			override.Multiplier = 1
+		}
+	}
+	return nil
+}
+
+// Validate checks the holiday date
+func (h *Holiday) Validate() error {
+	if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+		return errors.New("Date must be formatted as YYYY-MM-DD")
+	}
+	if strings.TrimSpace(h.Name) == "" {
+		return errors.New("Holiday name is required")
+	}
+	return nil
+}
+
+// InForce reports whether the rule was in force at the given time
+func (s *Surcharge) InForce(at time.Time) bool {
+	if s.EffectiveFrom != nil && at.Before(*s.EffectiveFrom) {
+		return false
+	}
+	return s.EffectiveUntil == nil || at.Before(*s.EffectiveUntil)
+}
+
+// Applies reports whether the rule applies at the given local time. isHoliday
+// tells whether a date (YYYY-MM-DD) is a holiday.
+func (s *Surcharge) Applies(local time.Time, isHoliday func(date string) bool) bool {
+	if s.Disabled {
+		return false
+	}
+
+	day := local
+	if s.Start != "" {
+		start, _ := clockMinutes(s.Start)
+		end, _ := clockMinutes(s.End)
+		minute := local.Hour()*60 + local.Minute()
+		if start <= end {
+			if minute < start || minute > end {
+				return false
+			}
+		} else {
+			if minute < start && minute > end {
+				return false
+			}
+			if minute <= end {
+				// After midnight the window belongs to the previous day
+				day = local.AddDate(0, 0, -1)
+			}
+		}
+	}
+
+	if len(s.Days) > 0 {
+		matched := false
+		for _, name := range s.Days {
+			if weekdays[name] == day.Weekday() {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	switch s.Holidays {
+	case HolidaysOnly:
+		return isHoliday(day.Format("2006-01-02"))
+	case HolidaysExcept:
+		return !isHoliday(day.Format("2006-01-02"))
+	}
+	return true
+}
+
+// Effect returns the multiplier and add-on of the rule for rides on a route
+func (s *Surcharge) Effect(routeID string) (float64, Money) {
+	for _, override := range s.Overrides {
+		if override.RouteID.Hex() == routeID {
+			return override.Multiplier, override.AddOn
+		}
+	}
+	return s.Multiplier, s.AddOn
+}
+
+// clockMinutes parses HH:MM into minutes after midnight
+func clockMinutes(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+var addisAbaba = time.FixedZone("EAT", 3*60*60)
+
+func at(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, addisAbaba)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSurchargeAppliesAcrossMidnight(t *testing.T) {
+	// 2024-09-27 is a Friday, and Meskel
+	friday := Surcharge{Name: "late friday", Days: []string{"fri"}, Start: "22:00", End: "02:00", Multiplier: 1.5}
+	holiday := Surcharge{Name: "holiday night", Start: "22:00", End: "02:00", Holidays: HolidaysOnly, Multiplier: 1.5}
+	workday := Surcharge{Name: "workday night", Start: "22:00", End: "02:00", Holidays: HolidaysExcept, Multiplier: 1.5}
+	evening := Surcharge{Name: SurchargeNight, Start: "18:30", End: "22:30", Multiplier: 1.4}
+	disabled := Surcharge{Name: "disabled", Start: "22:00", End: "02:00", Multiplier: 1.5, Disabled: true}
+	isHoliday := func(date string) bool { return date == "2024-09-27" }
+
+	tests := []struct {
+		name  string
+		rule  Surcharge
+		local string
+		want  bool
+	}{
+		{"before the window", friday, "2024-09-27 21:59", false},
+		{"start is inclusive", friday, "2024-09-27 22:00", true},
+		{"before midnight", friday, "2024-09-27 23:30", true},
+		{"after midnight belongs to the previous day", friday, "2024-09-28 01:30", true},
+		{"end is inclusive", friday, "2024-09-28 02:00", true},
+		{"after the window", friday, "2024-09-28 02:01", false},
+		{"after midnight into the day", friday, "2024-09-27 01:30", false},
+		{"before midnight on another day", friday, "2024-09-28 23:30", false},
+		{"holiday before midnight", holiday, "2024-09-27 23:00", true},
+		{"holiday after midnight", holiday, "2024-09-28 01:00", true},
+		{"after midnight into the holiday", holiday, "2024-09-27 01:00", false},
+		{"workday after midnight of a holiday", workday, "2024-09-28 01:00", false},
+		{"workday after midnight into the holiday", workday, "2024-09-27 01:00", true},
+		{"same-day window", evening, "2024-09-27 20:00", true},
+		{"same-day window after its end", evening, "2024-09-27 23:00", false},
+		{"disabled", disabled, "2024-09-27 23:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Applies(at(tt.local), isHoliday); got != tt.want {
+				t.Errorf("%s applies at %s = %t, want %t", tt.rule.Name, tt.local, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSurchargeInForce(t *testing.T) {
+	from, until := at("2024-01-01 00:00"), at("2025-01-01 00:00")
+	tests := []struct {
+		name  string
+		rule  Surcharge
+		local string
+		want  bool
+	}{
+		{"unbounded", Surcharge{}, "2020-01-01 00:00", true},
+		{"before it takes effect", Surcharge{EffectiveFrom: &from}, "2023-12-31 23:59", false},
+		{"when it takes effect", Surcharge{EffectiveFrom: &from}, "2024-01-01 00:00", true},
+		{"before it ends", Surcharge{EffectiveFrom: &from, EffectiveUntil: &until}, "2024-12-31 23:59", true},
+		{"when it ends", Surcharge{EffectiveFrom: &from, EffectiveUntil: &until}, "2025-01-01 00:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.InForce(at(tt.local)); got != tt.want {
+				t.Errorf("in force at %s = %t, want %t", tt.local, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Rounding modes for fares computed from the per-km rate
+const (
+	RoundFloor   = "floor"
+	RoundNearest = "nearest"
+	RoundCeil    = "ceil"
+	RoundNone    = "none"
+)
+
+// ErrEmptyTariff is returned when pricing with a tariff that has neither
+// brackets nor a per-km rate, such as an unvalidated stored one
+var ErrEmptyTariff = errors.New("tariff has no brackets or per-km rate")
+
+// FareBracket charges Fare for any distance up to UpToKm
+type FareBracket struct {
+	UpToKm float64 `json:"up_to_km" bson:"up_to_km"`
+	Fare   Money   `json:"fare" bson:"fare"`
+}
+
+// Tariff is a published distance fare table for one vehicle class
+type Tariff struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	VehicleClass  string             `json:"vehicle_class" bson:"vehicle_class"`
+	EffectiveFrom time.Time          `json:"effective_from" bson:"effective_from"`
+	Brackets      []FareBracket      `json:"brackets" bson:"brackets"`
+	// Beyond PerKmAboveKm the whole distance is charged at PerKmRate
+	PerKmAboveKm float64 `json:"per_km_above_km" bson:"per_km_above_km"`
+	PerKmRate    float64 `json:"per_km_rate" bson:"per_km_rate"`
+	Rounding     string  `json:"rounding" bson:"rounding"`
+	RoundingStep Money   `json:"rounding_step" bson:"rounding_step"`
+	// Source cites the publication the tariff comes from
+	Source    string    `json:"source,omitempty" bson:"source,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	// ValidUntil is when the next tariff of the class takes effect; it is derived, not stored
+	ValidUntil *time.Time `json:"valid_until,omitempty" bson:"-"`
+}
+
+// DefaultTariff is the minibus tariff used until one is published
+func DefaultTariff() Tariff {
+	tariff := Tariff{
+		VehicleClass: VehicleMinibus,
+		PerKmAboveKm: 30,
+		PerKmRate:    2.17,
+		Rounding:     RoundFloor,
+		RoundingStep: Birr,
+	}
+	// Fixed price brackets of 2.5 km starting at 10 Birr
+	for i := 1; i <= 12; i++ {
+		tariff.Brackets = append(tariff.Brackets, FareBracket{
+			UpToKm: 2.5 * float64(i),
+			Fare:   Money(5+5*i) * Birr,
+		})
+	}
+	return tariff
+}
+
+// Validate checks the tariff and fills in defaults
+func (t *Tariff) Validate() error {
+	if t.VehicleClass == "" {
+		t.VehicleClass = VehicleMinibus
+	}
+	if !ValidVehicleClass(t.VehicleClass) {
+		return errors.New("Unknown vehicle class")
+	}
+	if len(t.Brackets) == 0 && t.PerKmRate <= 0 {
+		return errors.New("Tariff needs brackets or a per-km rate")
+	}
+
+	sort.SliceStable(t.Brackets, func(i, j int) bool {
+		return t.Brackets[i].UpToKm < t.Brackets[j].UpToKm
+	})
+	for i, bracket := range t.Brackets {
+		if bracket.UpToKm <= 0 || bracket.Fare <= 0 {
+			return errors.New("Invalid fare bracket")
+		}
+		if i > 0 && bracket.UpToKm == t.Brackets[i-1].UpToKm {
+			return errors.New("Duplicate fare bracket")
+		}
+	}
+	if t.PerKmRate < 0 || t.PerKmAboveKm < 0 {
+		return errors.New("Invalid per-km rate")
+	}
+
+	switch t.Rounding {
+	case "":
+		t.Rounding = RoundFloor
+	case RoundFloor, RoundNearest, RoundCeil, RoundNone:
+	default:
+		return errors.New("Rounding must be one of floor, nearest, ceil, none")
+	}
+	if t.RoundingStep < 0 {
+		return errors.New("Invalid rounding step")
+	}
+	if t.RoundingStep == 0 {
+		t.RoundingStep = Birr
+	}
+	return nil
+}
+
+// Fare calculates the fare for a distance in kilometers
+func (t *Tariff) Fare(distance float64) (Money, error) {
+	if len(t.Brackets) == 0 && t.PerKmRate <= 0 {
+		return 0, ErrEmptyTariff
+	}
+	if distance < 0 {
+		return 0, nil
+	}
+
+	if t.PerKmRate <= 0 || distance <= t.PerKmAboveKm {
+		for _, bracket := range t.Brackets {
+			if distance <= bracket.UpToKm {
+				return bracket.Fare, nil
+			}
+		}
+	}
+	if t.PerKmRate <= 0 {
+		// Distances past the last bracket pay the highest fare
+		return t.Brackets[len(t.Brackets)-1].Fare, nil
+	}
+	return t.round(distance * t.PerKmRate), nil
+}
+
+// round rounds a fare in Birr to the tariff's rounding step
+func (t *Tariff) round(fare float64) Money {
+	santim := fare * float64(Birr)
+	step := float64(t.RoundingStep)
+	if step <= 0 {
+		step = float64(Birr)
+	}
+	switch t.Rounding {
+	case RoundNearest:
+		return Money(math.Round(santim/step) * step)
+	case RoundCeil:
+		return Money(math.Ceil(santim/step) * step)
+	case RoundNone:
+		return Money(math.Round(santim))
+	default:
+		return Money(math.Floor(santim/step) * step)
+	}
+}
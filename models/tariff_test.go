@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// calculateFare is the fixed fare table the default tariff replaced
+func calculateFare(distance float64) float64 {
+	if distance < 0 {
+		return 0
+	}
+	for i := 1; i <= 12; i++ {
+		if distance <= 2.5*float64(i) {
+			return float64(5 + 5*i)
+		}
+	}
+	return float64(int(distance * 2.17))
+}
+
+func TestDefaultTariffMatchesFixedFares(t *testing.T) {
+	tariff := DefaultTariff()
+	for tenths := -5; tenths <= 600; tenths++ {
+		distance := float64(tenths) / 10
+		got, err := tariff.Fare(distance)
+		if err != nil {
+			t.Fatalf("Fare(%.1f) error = %v", distance, err)
+		}
+		if want := FromBirr(calculateFare(distance)); got != want {
+			t.Errorf("Fare(%.1f) = %s, want %s", distance, got, want)
+		}
+	}
+}
+
+func TestTariffFare(t *testing.T) {
+	brackets := []FareBracket{{UpToKm: 5, Fare: 10 * Birr}, {UpToKm: 10, Fare: 20 * Birr}}
+	tests := []struct {
+		name     string
+		tariff   Tariff
+		distance float64
+		want     Money
+		wantErr  error
+	}{
+		{"first bracket", Tariff{Brackets: brackets}, 5, 10 * Birr, nil},
+		{"past the last bracket without a rate", Tariff{Brackets: brackets}, 25, 20 * Birr, nil},
+		{"per-km rate without brackets", Tariff{PerKmRate: 2, Rounding: RoundFloor, RoundingStep: Birr}, 7.8, 15 * Birr, nil},
+		{"no brackets and no rate", Tariff{}, 5, 0, ErrEmptyTariff},
+		{"no brackets and a negative rate", Tariff{PerKmRate: -1}, 5, 0, ErrEmptyTariff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tariff.Fare(tt.distance)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Fare(%v) error = %v, want %v", tt.distance, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Fare(%v) = %s, want %s", tt.distance, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Vehicle classes serving routes
+const (
+	// VehicleMinibus is the blue-and-white minibus taxi class, the default for fares
+	VehicleMinibus  = "minibus"
+	VehicleMidibus  = "midibus"
+	VehicleAnbessa  = "anbessa"
+	VehicleRideHail = "ride_hail"
+)
+
+// VehicleClasses lists the supported vehicle classes
+var VehicleClasses = []string{VehicleMinibus, VehicleMidibus, VehicleAnbessa, VehicleRideHail}
+
+// ValidVehicleClass reports whether class is a supported vehicle class
+func ValidVehicleClass(class string) bool {
+	for _, known := range VehicleClasses {
+		if class == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Service is a vehicle class running along a route at its own fare
+type Service struct {
+	VehicleClass string `json:"vehicleClass" bson:"vehicleClass"`
+	Price        Money  `json:"price" bson:"price"`
+	// FrequencyMinutes is the usual time between vehicles, 0 if unknown
+	FrequencyMinutes int `json:"frequencyMinutes,omitempty" bson:"frequencyMinutes,omitempty"`
+}
+
+// AllServices lists the vehicle classes serving the route. Price is the
+// minibus fare unless Services lists the minibus itself.
+func (r *Route) AllServices() []Service {
+	services := make([]Service, 0, len(r.Services)+1)
+	minibus := false
+	for _, service := range r.Services {
+		if service.VehicleClass == VehicleMinibus {
+			minibus = true
+		}
+	}
+	if !minibus && r.Price > 0 {
+		services = append(services, Service{VehicleClass: VehicleMinibus, Price: r.Price})
+	}
+	return append(services, r.Services...)
+}
+
+// validateServices checks the per vehicle class fares of a route
+func validateServices(services []Service) error {
+	seen := make(map[string]bool)
+	for _, service := range services {
+		if !ValidVehicleClass(service.VehicleClass) {
+			return fmt.Errorf("Unknown vehicle class %q", service.VehicleClass)
+		}
+		if seen[service.VehicleClass] {
+			return fmt.Errorf("Vehicle class %s is listed more than once", service.VehicleClass)
+		}
+		seen[service.VehicleClass] = true
+		if service.Price <= 0 || service.FrequencyMinutes < 0 {
+			return errors.New("Invalid service data")
+		}
+	}
+	return nil
+}
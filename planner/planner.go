@@ -0,0 +1,312 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"taxi-fare-calculator/fares"
+	"taxi-fare-calculator/graph"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/roads"
+	"taxi-fare-calculator/search"
+	"time"
+)
+
+var (
+	// ErrUnknownStation is returned when a requested station does not exist
+	ErrUnknownStation = errors.New("station not found")
+	// ErrNoRoute is returned when two stations cannot be connected
+	ErrNoRoute = errors.New("no route found")
+)
+
+// Request describes a journey to plan
+type Request struct {
+	From string
+	To   string
+	// FromPoint and ToPoint, when set, replace From and To: the journey starts
+	// or ends with a walk to one of the stations nearest to the point
+	FromPoint *Point
+	ToPoint   *Point
+	// At is the time of travel; zero means now. Journeys in the past are
+	// priced with the routes and tariffs in force at that time.
+	At time.Time
+	// Modes restricts the vehicle classes taken; empty allows all
+	Modes []string
+}
+
+// Planner plans journeys over the route graph. It is the single source of
+// journeys and fares for every endpoint.
+type Planner struct {
+	graph    *graph.Cache
+	fares    *fares.Engine
+	history  *fares.History
+	roads    *roads.Cache
+	stations models.StationStore
+	options  Options
+}
+
+func New(g *graph.Cache, fareEngine *fares.Engine, history *fares.History, roadCache *roads.Cache, stations models.StationStore, options Options) *Planner {
+	return &Planner{graph: g, fares: fareEngine, history: history, roads: roadCache, stations: stations, options: options}
+}
+
+// Plan returns the best journey between two stations, or door to door between
+// two points. When no stations are connected by any route the fare is
+// estimated from the road distance between the nearest ones.
+func (p *Planner) Plan(ctx context.Context, req Request) (*models.Journey, error) {
+	q, err := p.resolve(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var journey models.Journey
+	from, to, result := p.pick(q)
+	if result != nil {
+		journey = p.fromGraph(q.network, result)
+	} else {
+		var ok bool
+		from, to, ok = nearestPair(q)
+		if !ok {
+			return nil, ErrNoRoute
+		}
+		estimate, err := p.estimate(ctx, q.network, from.station, to.station, req)
+		if err != nil {
+			return nil, err
+		}
+		journey = *estimate
+	}
+
+	if err := p.price(ctx, &journey, req.At, q.events); err != nil {
+		return nil, err
+	}
+	journey.WalkStart, journey.WalkEnd = from.walk, to.walk
+	return &journey, nil
+}
+
+// Alternatives returns up to k journeys between two stations ranked by
+// sortBy. Between points, they run between the stations of the best journey.
+func (p *Planner) Alternatives(ctx context.Context, req Request, k int, sortBy string) ([]models.Journey, error) {
+	q, err := p.resolve(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, best := p.pick(q)
+	if best == nil {
+		return nil, ErrNoRoute
+	}
+	results := q.network.Alternatives(from.station, to.station, k, sortBy)
+	if len(results) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	journeys := make([]models.Journey, 0, len(results))
+	for _, result := range results {
+		journey := p.fromGraph(q.network, &result)
+		if err := p.price(ctx, &journey, req.At, q.events); err != nil {
+			return nil, err
+		}
+		journey.WalkStart, journey.WalkEnd = from.walk, to.walk
+		journeys = append(journeys, journey)
+	}
+	return journeys, nil
+}
+
+// query is a request resolved against the network in force at its time
+type query struct {
+	network      *graph.Graph
+	origins      []endpoint
+	destinations []endpoint
+	events       []models.FareEvent
+}
+
+// resolve loads the graph and fare events and finds the stations the journey may start and end at
+func (p *Planner) resolve(ctx context.Context, req Request) (*query, error) {
+	events, err := p.fares.EventsAt(ctx, req.At)
+	if err != nil {
+		return nil, err
+	}
+	network, err := p.network(ctx, req.At, events)
+	if err != nil {
+		return nil, err
+	}
+	network = network.WithModes(req.Modes)
+
+	origins, err := p.endpoints(ctx, network, req.From, req.FromPoint, true)
+	if err != nil {
+		return nil, err
+	}
+	destinations, err := p.endpoints(ctx, network, req.To, req.ToPoint, false)
+	if err != nil {
+		return nil, err
+	}
+	return &query{network: network, origins: origins, destinations: destinations, events: events}, nil
+}
+
+// network returns the route graph in force at the given time, without the
+// routes suspended by fare events
+func (p *Planner) network(ctx context.Context, at time.Time, events []models.FareEvent) (*graph.Graph, error) {
+	suspensions := false
+	for _, event := range events {
+		if len(event.SuspendedRoutes) > 0 {
+			suspensions = true
+		}
+	}
+	current := at.IsZero() || !at.Before(time.Now())
+	if current && !suspensions {
+		return p.graph.Get(ctx)
+	}
+
+	routes, err := p.history.Routes(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+	open := routes[:0]
+	for _, route := range routes {
+		suspended := false
+		for _, event := range events {
+			if event.Suspends(route.ID) {
+				suspended = true
+			}
+		}
+		if !suspended {
+			open = append(open, route)
+		}
+	}
+	return p.graph.BuildWith(ctx, open)
+}
+
+// price applies the surcharges and fare events in force at the time of travel,
+// rounds the fares to what drivers charge and scores how far they can be trusted
+func (p *Planner) price(ctx context.Context, journey *models.Journey, at time.Time, events []models.FareEvent) error {
+	if err := p.fares.ApplySurcharges(ctx, journey, at); err != nil {
+		return err
+	}
+	fares.ApplyEvents(journey, events)
+	p.fares.Round(journey)
+
+	routeIDs := make([]string, len(journey.Rides))
+	for i, ride := range journey.Rides {
+		routeIDs[i] = ride.RouteID
+	}
+	verified, err := p.history.VerifiedAt(ctx, routeIDs, at)
+	if err != nil {
+		return err
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	fares.Assess(journey, verified, at)
+	return nil
+}
+
+// Search ranks the stations matching what a rider typed, the same way the
+// names given to Plan are resolved
+func (p *Planner) Search(ctx context.Context, query string, limit int) ([]search.Match, error) {
+	network, err := p.graph.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return network.Search(query, limit), nil
+}
+
+// resolveName finds the station a rider typed, by name, alias, slug or ID,
+// tolerating typos and other spellings. The error suggests the closest
+// stations when the name is unknown or ambiguous.
+func resolveName(network *graph.Graph, name string) (string, error) {
+	station, suggestions, ok := network.Resolve(name)
+	if ok {
+		return station, nil
+	}
+	if len(suggestions) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrUnknownStation, name)
+	}
+	names := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		names[i] = suggestion.Station.Name
+	}
+	return "", fmt.Errorf("%w: %s, did you mean %s?", ErrUnknownStation, name, strings.Join(names, ", "))
+}
+
+func (p *Planner) fromGraph(network *graph.Graph, result *graph.Journey) models.Journey {
+	journey := models.Journey{
+		Rides:      result.Rides,
+		Legs:       result.Legs,
+		TotalPrice: result.TotalPrice,
+		Transfers:  result.Transfers,
+		DistanceKm: result.DistanceKm,
+	}
+	for _, name := range result.Route {
+		journey.Stations = append(journey.Stations, stationDetails(network, name))
+	}
+	return journey
+}
+
+// estimate prices a journey between unconnected stations from the road
+// distance, using the tariff in force at the time of travel for the first
+// allowed vehicle class that has one
+func (p *Planner) estimate(ctx context.Context, network *graph.Graph, from, to string, req Request) (*models.Journey, error) {
+	fromStation, okFrom := network.Station(from)
+	toStation, okTo := network.Station(to)
+	if !okFrom || !okTo || len(fromStation.Location.Coordinates) != 2 || len(toStation.Location.Coordinates) != 2 {
+		return nil, ErrNoRoute
+	}
+
+	road, err := p.roads.Leg(ctx, fromStation, toStation)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoRoute, err)
+	}
+	distance := road.Distance / 1000
+
+	classes := req.Modes
+	if len(classes) == 0 {
+		classes = []string{models.VehicleMinibus}
+	}
+	var vehicleClass string
+	var fare models.Money
+	for _, class := range classes {
+		var err error
+		fare, err = p.fares.CalculateFare(ctx, class, distance, req.At)
+		if errors.Is(err, fares.ErrNoTariff) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		vehicleClass = class
+		break
+	}
+	if vehicleClass == "" {
+		return nil, ErrNoRoute
+	}
+
+	return &models.Journey{
+		Stations: []models.Station{fromStation, toStation},
+		Rides: []models.Ride{{
+			VehicleClass: vehicleClass,
+			From:         from,
+			To:           to,
+			Stops:        []string{from, to},
+			Price:        fare,
+		}},
+		Legs: []models.RouteLeg{{
+			From:         from,
+			To:           to,
+			Price:        fare,
+			VehicleClass: vehicleClass,
+			Source:       models.LegSourceEstimate,
+		}},
+		TotalPrice: fare,
+		DistanceKm: distance,
+		Estimated:  true,
+	}, nil
+}
+
+// stationDetails returns the stored station, or one with only a name when a
+// route references a station that was never added
+func stationDetails(network *graph.Graph, name string) models.Station {
+	if station, ok := network.Station(name); ok {
+		return station
+	}
+	return models.Station{Name: name}
+}
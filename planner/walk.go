@@ -0,0 +1,164 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"taxi-fare-calculator/graph"
+	"taxi-fare-calculator/models"
+)
+
+// ErrNoStationNearby is returned when no station is within walking distance of a point
+var ErrNoStationNearby = errors.New("no station within walking distance")
+
+// walkSpeed is the walking speed in meters per second, about 5 km/h
+const walkSpeed = 5000.0 / 3600
+
+// Point is a position given as longitude and latitude
+type Point struct {
+	Lng float64
+	Lat float64
+}
+
+// Options tune door-to-door planning
+type Options struct {
+	// Candidates is how many of the nearest stations are considered at each end
+	Candidates int
+	// MaxWalk is the longest walk, in meters, to or from a station
+	MaxWalk float64
+	// WalkPenalty is the cost in Birr of a minute of walking, weighed against fares
+	WalkPenalty float64
+	// DetourFactor stretches straight-line walks into walks along streets
+	DetourFactor float64
+}
+
+// endpoint is a station a journey can start or end at, with the walk between
+// it and the requested point; walk is nil for stations requested by name
+type endpoint struct {
+	station string
+	walk    *models.Walk
+}
+
+// endpoints returns the stations a journey may start (or end) at: the named
+// station, or the stations within walking distance of the point, nearest first
+func (p *Planner) endpoints(ctx context.Context, network *graph.Graph, name string, point *Point, start bool) ([]endpoint, error) {
+	if point == nil {
+		station, err := resolveName(network, name)
+		if err != nil {
+			return nil, err
+		}
+		return []endpoint{{station: station}}, nil
+	}
+
+	detour := math.Max(p.options.DetourFactor, 1)
+	nearest, err := p.stations.NearestStations(ctx, point.Lng, point.Lat, p.options.MaxWalk/detour, p.options.Candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []endpoint
+	for _, candidate := range nearest {
+		station := candidate.Station
+		if !network.HasStation(station.Name) {
+			continue
+		}
+		distance, duration := p.EstimateWalk(candidate.Distance)
+		coordinates := [][]float64{{point.Lng, point.Lat}, station.Location.Coordinates}
+		if !start {
+			coordinates[0], coordinates[1] = coordinates[1], coordinates[0]
+		}
+		endpoints = append(endpoints, endpoint{
+			station: station.Name,
+			walk: &models.Walk{
+				Station:     station.Name,
+				Distance:    distance,
+				Duration:    duration,
+				Coordinates: coordinates,
+			},
+		})
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%w of %g, %g", ErrNoStationNearby, point.Lat, point.Lng)
+	}
+	return endpoints, nil
+}
+
+// EstimateWalk estimates the distance in meters and time in seconds of a walk
+// along the streets between two points a straight-line distance apart
+func (p *Planner) EstimateWalk(straight float64) (distance, duration float64) {
+	distance = straight * math.Max(p.options.DetourFactor, 1)
+	return distance, distance / walkSpeed
+}
+
+// walkCost is the cost in Birr of walking to or from an endpoint
+func (p *Planner) walkCost(e endpoint) float64 {
+	if e.walk == nil {
+		return 0
+	}
+	return e.walk.Duration / 60 * p.options.WalkPenalty
+}
+
+// pick chooses the origin and destination stations giving the cheapest
+// journey, counting the walks at both ends. A nil result means no pair of
+// stations is connected.
+func (p *Planner) pick(q *query) (from, to endpoint, result *graph.Journey) {
+	best := math.Inf(1)
+	for _, origin := range q.origins {
+		for _, destination := range q.destinations {
+			if origin.station == destination.station && (origin.walk != nil || destination.walk != nil) {
+				continue
+			}
+			journey, found := q.network.ShortestPath(origin.station, destination.station)
+			if !found {
+				continue
+			}
+			cost := journey.TotalPrice.Birr() + p.walkCost(origin) + p.walkCost(destination)
+			if cost < best {
+				best = cost
+				from, to, result = origin, destination, journey
+			}
+		}
+	}
+	return from, to, result
+}
+
+// nearestPair returns the closest origin and destination that are different stations
+func nearestPair(q *query) (from, to endpoint, ok bool) {
+	for _, origin := range q.origins {
+		for _, destination := range q.destinations {
+			if origin.station != destination.station {
+				return origin, destination, true
+			}
+		}
+	}
+	return endpoint{}, endpoint{}, false
+}
+
+// Served keeps the stations served by the given vehicle classes (any if
+// none) and, when to is set, that have a ride to that station without changing
+func (p *Planner) Served(ctx context.Context, stations []models.StationDistance, to string, modes []string) ([]models.StationDistance, error) {
+	network, err := p.graph.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	network = network.WithModes(modes)
+
+	destination := ""
+	if to != "" {
+		var err error
+		if destination, err = resolveName(network, to); err != nil {
+			return nil, err
+		}
+	}
+
+	served := stations[:0:0]
+	for _, station := range stations {
+		name := station.Station.Name
+		if !network.Serves(name) || (destination != "" && (name == destination || !network.RidesTo(name, destination))) {
+			continue
+		}
+		served = append(served, station)
+	}
+	return served, nil
+}
@@ -0,0 +1,106 @@
+package planner
+
+import (
+	"math"
+	"taxi-fare-calculator/graph"
+	"taxi-fare-calculator/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEstimateWalk(t *testing.T) {
+	tests := []struct {
+		name         string
+		detour       float64
+		straight     float64
+		wantDistance float64
+	}{
+		{"streets detour", 1.4, 1000, 1400},
+		{"no detour factor walks straight", 0, 1000, 1000},
+		{"detours never shorten walks", 0.5, 1000, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Planner{options: Options{DetourFactor: tt.detour}}
+			distance, duration := p.EstimateWalk(tt.straight)
+			if distance != tt.wantDistance {
+				t.Errorf("distance = %v, want %v", distance, tt.wantDistance)
+			}
+			// Walking at 5 km/h covers 1.2 km in 864 seconds
+			if want := tt.wantDistance * 0.72; math.Abs(duration-want) > 1e-9 {
+				t.Errorf("duration = %v, want %v", duration, want)
+			}
+		})
+	}
+}
+
+func TestPickWeighsWalksAgainstFares(t *testing.T) {
+	stations := []models.Station{
+		{Name: "Mexico Station"},
+		{Name: "Sebategna Station"},
+		{Name: "Piassa Station"},
+	}
+	routes := []models.Route{
+		{ID: primitive.NewObjectID(), From: "Mexico Station", To: "Piassa Station", Price: 30 * models.Birr, IsDirectRoute: true},
+		{ID: primitive.NewObjectID(), From: "Sebategna Station", To: "Piassa Station", Price: 10 * models.Birr, IsDirectRoute: true},
+	}
+	network := graph.Build(stations, routes, graph.Options{})
+	walkTo := func(station string, minutes float64) endpoint {
+		return endpoint{station: station, walk: &models.Walk{Station: station, Duration: minutes * 60}}
+	}
+
+	// Mexico is a 2 minute walk away and costs 30 Birr to Piassa, Sebategna is
+	// a 12 minute walk away and costs 10 Birr
+	q := &query{
+		network:      network,
+		origins:      []endpoint{walkTo("Mexico Station", 2), walkTo("Sebategna Station", 12)},
+		destinations: []endpoint{{station: "Piassa Station"}},
+	}
+	tests := []struct {
+		name    string
+		penalty float64
+		want    string
+	}{
+		{"free walking takes the cheapest fare", 0, "Sebategna Station"},
+		{"cheap walking takes the cheapest fare", 1, "Sebategna Station"},
+		{"costly walking takes the nearest station", 3, "Mexico Station"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Planner{options: Options{WalkPenalty: tt.penalty}}
+			from, to, result := p.pick(q)
+			if result == nil {
+				t.Fatal("pick() found no journey")
+			}
+			if from.station != tt.want || to.station != "Piassa Station" {
+				t.Errorf("pick() = %s -> %s, want %s -> Piassa Station", from.station, to.station, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickSkipsWalkingToTheSameStation(t *testing.T) {
+	stations := []models.Station{{Name: "Mexico Station"}, {Name: "Piassa Station"}}
+	routes := []models.Route{
+		{ID: primitive.NewObjectID(), From: "Mexico Station", To: "Piassa Station", Price: 10 * models.Birr, IsDirectRoute: true},
+	}
+	p := &Planner{options: Options{WalkPenalty: 1}}
+	walkTo := endpoint{station: "Piassa Station", walk: &models.Walk{Station: "Piassa Station", Duration: 60}}
+
+	// Both points are nearest to Piassa, which is no journey at all
+	q := &query{
+		network:      graph.Build(stations, routes, graph.Options{}),
+		origins:      []endpoint{walkTo},
+		destinations: []endpoint{walkTo},
+	}
+	if _, _, result := p.pick(q); result != nil {
+		t.Errorf("pick() = %v, want no journey", result)
+	}
+
+	q.origins = append(q.origins, endpoint{station: "Mexico Station", walk: &models.Walk{Station: "Mexico Station", Duration: 600}})
+	from, to, result := p.pick(q)
+	if result == nil || from.station != "Mexico Station" || to.station != "Piassa Station" {
+		t.Errorf("pick() = %s -> %s, want Mexico Station -> Piassa Station", from.station, to.station)
+	}
+}
@@ -0,0 +1,189 @@
+package roads
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxFetches bounds the router requests made at once for a single journey
+const maxFetches = 4
+
+// ErrNoLocation is returned for stations without coordinates
+var ErrNoLocation = errors.New("station has no location")
+
+// Cache keeps the road routes between stations in a store so that each pair
+// is routed once. Entries expire after ttl and are dropped when a station
+// moves. Approximate routes are never kept.
+type Cache struct {
+	router utils.Router
+	store  models.RoadLegStore
+	ttl    time.Duration
+}
+
+func NewCache(router utils.Router, store models.RoadLegStore, ttl time.Duration) *Cache {
+	return &Cache{router: router, store: store, ttl: ttl}
+}
+
+// Leg returns the road route from one station to another
+func (c *Cache) Leg(ctx context.Context, from, to models.Station) (*utils.RoadRoute, error) {
+	if len(from.Location.Coordinates) != 2 || len(to.Location.Coordinates) != 2 {
+		return nil, ErrNoLocation
+	}
+	cacheable := !from.ID.IsZero() && !to.ID.IsZero()
+	hash := legHash(from, to)
+
+	if cacheable {
+		leg, err := c.store.GetRoadLeg(ctx, from.ID, to.ID)
+		switch {
+		case err == nil && leg.Hash == hash && (c.ttl <= 0 || time.Since(leg.FetchedAt) < c.ttl):
+			return &utils.RoadRoute{
+				Distance: leg.Distance,
+				Duration: leg.Duration,
+				Geometry: &utils.LineString{Type: "LineString", Coordinates: leg.Coordinates},
+			}, nil
+		case err != nil && !errors.Is(err, models.ErrNotFound):
+			log.Printf("⚠️ Failed to read cached road leg %s -> %s: %v", from.Name, to.Name, err)
+		}
+	}
+
+	route, err := c.router.Route(ctx,
+		from.Location.Coordinates[0], from.Location.Coordinates[1],
+		to.Location.Coordinates[0], to.Location.Coordinates[1],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable && !route.Approximate {
+		leg := models.RoadLeg{
+			From:      from.ID,
+			To:        to.ID,
+			Hash:      hash,
+			Distance:  route.Distance,
+			Duration:  route.Duration,
+			FetchedAt: time.Now().UTC(),
+		}
+		if route.Geometry != nil {
+			leg.Coordinates = route.Geometry.Coordinates
+		}
+		if err := c.store.SaveRoadLeg(ctx, &leg); err != nil {
+			log.Printf("⚠️ Failed to cache road leg %s -> %s: %v", from.Name, to.Name, err)
+		}
+	}
+	return route, nil
+}
+
+// Legs returns the road routes between consecutive stations, routing the
+// uncached ones concurrently. Legs that cannot be routed are nil.
+func (c *Cache) Legs(ctx context.Context, stations []models.Station) []*utils.RoadRoute {
+	if len(stations) < 2 {
+		return nil
+	}
+	legs := make([]*utils.RoadRoute, len(stations)-1)
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxFetches)
+	for i := range legs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			route, err := c.Leg(ctx, stations[i], stations[i+1])
+			if err != nil && !errors.Is(err, ErrNoLocation) {
+				log.Printf("⚠️ No road route from %s to %s: %v", stations[i].Name, stations[i+1].Name, err)
+			}
+			legs[i] = route
+		}(i)
+	}
+	wg.Wait()
+	return legs
+}
+
+// legHash identifies the coordinates of a pair of stations
+func legHash(from, to models.Station) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%.6f,%.6f;%.6f,%.6f",
+		from.Location.Coordinates[0], from.Location.Coordinates[1],
+		to.Location.Coordinates[0], to.Location.Coordinates[1],
+	)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// WrapStations returns a station store that drops the cached legs of stations
+// that move or are deleted
+func (c *Cache) WrapStations(store models.StationStore) models.StationStore {
+	return &stationStore{StationStore: store, cache: c}
+}
+
+type stationStore struct {
+	models.StationStore
+	cache *Cache
+}
+
+func (s *stationStore) UpdateStation(ctx context.Context, id primitive.ObjectID, station *models.Station) error {
+	old, err := s.StationStore.GetStation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.StationStore.UpdateStation(ctx, id, station); err != nil {
+		return err
+	}
+	if moved(old, station) {
+		s.invalidate(ctx, id)
+	}
+	return nil
+}
+
+func (s *stationStore) ReplaceStation(ctx context.Context, station *models.Station) error {
+	old, err := s.StationStore.GetStation(ctx, station.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.StationStore.ReplaceStation(ctx, station); err != nil {
+		return err
+	}
+	if moved(old, station) {
+		s.invalidate(ctx, station.ID)
+	}
+	return nil
+}
+
+func (s *stationStore) DeleteStation(ctx context.Context, id primitive.ObjectID) error {
+	if err := s.StationStore.DeleteStation(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id)
+	return nil
+}
+
+// invalidate drops the legs of a station after a write that already
+// succeeded; legs left behind are caught by their hash anyway
+func (s *stationStore) invalidate(ctx context.Context, id primitive.ObjectID) {
+	if err := s.cache.store.DeleteRoadLegs(ctx, id); err != nil {
+		log.Printf("⚠️ Failed to drop cached road legs of station %s: %v", id.Hex(), err)
+	}
+}
+
+func moved(old, new *models.Station) bool {
+	a, b := old.Location.Coordinates, new.Location.Coordinates
+	if len(a) != len(b) {
+		return true
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package roads
+
+import (
+	"context"
+	"errors"
+	"taxi-fare-calculator/database"
+	"taxi-fare-calculator/models"
+	"taxi-fare-calculator/utils"
+	"testing"
+	"time"
+)
+
+// countingRouter routes in a straight line and counts the requests it serves
+type countingRouter struct {
+	requests int
+}
+
+func (r *countingRouter) Route(ctx context.Context, fromLng, fromLat, toLng, toLat float64) (*utils.RoadRoute, error) {
+	r.requests++
+	return &utils.RoadRoute{Distance: utils.HaversineDistance(fromLat, fromLng, toLat, toLng) * 1000}, nil
+}
+
+func station(name string, lng, lat float64) models.Station {
+	return models.Station{Name: name, Location: models.Location{Type: "Point", Coordinates: []float64{lng, lat}}}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name string
+		// change writes to the first station through the wrapped store
+		change       func(ctx context.Context, stations models.StationStore, station models.Station) error
+		wantCached   bool
+		wantRequests int
+	}{
+		{
+			name: "renamed station keeps its legs",
+			change: func(ctx context.Context, stations models.StationStore, station models.Station) error {
+				station.Name = "Mexico Square Station"
+				return stations.UpdateStation(ctx, station.ID, &station)
+			},
+			wantCached:   true,
+			wantRequests: 1,
+		},
+		{
+			name: "moved station drops its legs",
+			change: func(ctx context.Context, stations models.StationStore, station models.Station) error {
+				station.Location.Coordinates = []float64{38.7460, 9.0105}
+				return stations.UpdateStation(ctx, station.ID, &station)
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "replaced station at a new place drops its legs",
+			change: func(ctx context.Context, stations models.StationStore, station models.Station) error {
+				station.Location.Coordinates = []float64{38.7460, 9.0105}
+				return stations.ReplaceStation(ctx, &station)
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "deleted station drops its legs",
+			change: func(ctx context.Context, stations models.StationStore, station models.Station) error {
+				return stations.DeleteStation(ctx, station.ID)
+			},
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := database.NewMemoryStore()
+			router := &countingRouter{}
+			cache := NewCache(router, store, time.Hour)
+			stations := cache.WrapStations(store)
+
+			from, to := station("Mexico Station", 38.7449, 9.0101), station("Piassa Station", 38.7525, 9.0350)
+			for _, s := range []*models.Station{&from, &to} {
+				if err := stations.InsertStation(ctx, s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := cache.Leg(ctx, from, to); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.change(ctx, stations, from); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := store.GetRoadLeg(ctx, from.ID, to.ID)
+			if cached := err == nil; cached != tt.wantCached {
+				t.Errorf("leg cached after the change = %v, want %v", cached, tt.wantCached)
+			}
+			if err != nil && !errors.Is(err, models.ErrNotFound) {
+				t.Fatal(err)
+			}
+
+			// Routing the original pair again only reaches the router without a cached leg
+			if _, err := cache.Leg(ctx, from, to); err != nil {
+				t.Fatal(err)
+			}
+			if router.requests != tt.wantRequests {
+				t.Errorf("router requests = %d, want %d", router.requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestCacheRefetchesMovedStationsWithStaleLegs(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	router := &countingRouter{}
+	cache := NewCache(router, store, 0)
+
+	from, to := station("Mexico Station", 38.7449, 9.0101), station("Piassa Station", 38.7525, 9.0350)
+	for _, s := range []*models.Station{&from, &to} {
+		if err := store.InsertStation(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cache.Leg(ctx, from, to); err != nil {
+		t.Fatal(err)
+	}
+
+	// A move written around the wrapped store leaves the leg behind, but its
+	// hash no longer matches the coordinates
+	from.Location.Coordinates = []float64{38.7460, 9.0105}
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Leg(ctx, from, to); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if router.requests != 2 {
+		t.Errorf("router requests = %d, want 2", router.requests)
+	}
+}
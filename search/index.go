@@ -0,0 +1,206 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"taxi-fare-calculator/models"
+)
+
+// Kinds of match, from the strongest
+const (
+	MatchExact    = "exact"    // the name, an alias, the slug or the ID
+	MatchPhonetic = "phonetic" // spelled differently or in another script
+	MatchPrefix   = "prefix"   // the start of a name or of one of its words
+	MatchFuzzy    = "fuzzy"    // a name with typos
+)
+
+const (
+	// ambiguityMargin is how close the two best matches of a query must
+	// score for the query to be considered ambiguous
+	ambiguityMargin = 0.05
+	// minGuessLength is the shortest query, in letters of its key, that is
+	// resolved to a station it does not name exactly
+	minGuessLength = 3
+)
+
+// Match is a station found by a search
+type Match struct {
+	Station models.Station `json:"station"`
+	Score   float64        `json:"score"`
+	Kind    string         `json:"match"`
+	// Matched is the name or alias the query matched
+	Matched string `json:"matched"`
+}
+
+// Index finds stations by what riders type: names, aliases, slugs and IDs,
+// in Latin or Ethiopic script, with or without the " Station" suffix, and
+// with typos
+type Index struct {
+	stations []models.Station
+	terms    []term
+}
+
+// term is a name a station is known by
+type term struct {
+	station int
+	name    string
+	folded  string
+	key     string
+	words   []string
+}
+
+func NewIndex(stations []models.Station) *Index {
+	x := &Index{stations: stations}
+	for i, station := range stations {
+		names := []string{station.Name}
+		for _, alias := range station.Aliases {
+			names = append(names, alias.Name)
+		}
+		for _, name := range names {
+			folded := fold(name)
+			if folded == "" {
+				continue
+			}
+			x.terms = append(x.terms, term{
+				station: i,
+				name:    name,
+				folded:  folded,
+				key:     key(folded),
+				words:   strings.Fields(folded),
+			})
+		}
+	}
+	return x
+}
+
+// Lookup finds the station a name exactly refers to: its name with or without
+// the " Station" suffix, an alias, the slug or the ID. Unlike Search it never
+// guesses, so it is safe for canonicalizing stored names.
+func (x *Index) Lookup(name string) (models.Station, bool) {
+	name = strings.TrimSpace(name)
+	for _, station := range x.stations {
+		if station.Name == name || (station.Slug != "" && station.Slug == name) || station.ID.Hex() == name {
+			return station, true
+		}
+	}
+	folded := fold(name)
+	if folded == "" {
+		return models.Station{}, false
+	}
+	for _, t := range x.terms {
+		if t.folded == folded {
+			return x.stations[t.station], true
+		}
+	}
+	return models.Station{}, false
+}
+
+// Search ranks the stations matching a query, best first, returning at most
+// limit of them; limit 0 means no limit. Each station appears once, with the
+// best of its names.
+func (x *Index) Search(query string, limit int) []Match {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	best := make(map[int]Match)
+	consider := func(i int, m Match) {
+		if current, ok := best[i]; !ok || m.Score > current.Score {
+			best[i] = m
+		}
+	}
+
+	for i, station := range x.stations {
+		if (station.Slug != "" && station.Slug == query) || station.ID.Hex() == query {
+			consider(i, Match{Station: station, Score: 1, Kind: MatchExact, Matched: query})
+		}
+	}
+
+	folded := fold(query)
+	queryKey := key(folded)
+	if queryKey != "" {
+		for _, t := range x.terms {
+			if score, kind, ok := t.score(folded, queryKey); ok {
+				consider(t.station, Match{Station: x.stations[t.station], Score: score, Kind: kind, Matched: t.name})
+			}
+		}
+	}
+
+	matches := make([]Match, 0, len(best))
+	for _, m := range best {
+		m.Score = float64(int(m.Score*1000+0.5)) / 1000
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if len(matches[i].Station.Name) != len(matches[j].Station.Name) {
+			return len(matches[i].Station.Name) < len(matches[j].Station.Name)
+		}
+		return matches[i].Station.Name < matches[j].Station.Name
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Resolve returns the station a query most likely names. It fails when
+// nothing matches, when a short query does not name a station exactly or
+// when the best matches score too close to tell apart, returning the
+// closest stations as suggestions.
+func (x *Index) Resolve(query string) (models.Station, []Match, bool) {
+	matches := x.Search(query, 5)
+	if len(matches) == 0 {
+		return models.Station{}, nil, false
+	}
+	top := matches[0]
+	if top.Kind != MatchExact {
+		if len([]rune(key(fold(query)))) < minGuessLength {
+			return models.Station{}, matches, false
+		}
+		if len(matches) > 1 && matches[1].Score >= top.Score-ambiguityMargin {
+			return models.Station{}, matches, false
+		}
+	}
+	return top.Station, matches, true
+}
+
+// score rates how well a query matches the term
+func (t term) score(folded, queryKey string) (float64, string, bool) {
+	if folded == t.folded {
+		return 1, MatchExact, true
+	}
+	if queryKey == t.key {
+		return 0.9, MatchPhonetic, true
+	}
+
+	// Typing the start of the name, or of any of its words
+	if len(queryKey) >= 2 {
+		if strings.HasPrefix(t.key, queryKey) {
+			return 0.6 + 0.25*float64(len(queryKey))/float64(len(t.key)), MatchPrefix, true
+		}
+		for _, word := range t.words[1:] {
+			if strings.HasPrefix(word, folded) {
+				return 0.55 + 0.2*float64(len(folded))/float64(len(t.folded)), MatchPrefix, true
+			}
+		}
+	}
+
+	// Typos in the whole name, or in the start of it
+	allowed := maxEdits(len(queryKey))
+	if allowed == 0 {
+		return 0, "", false
+	}
+	if d := distance(queryKey, t.key); d <= allowed {
+		return 0.75 * (1 - float64(d)/float64(len(queryKey)+1)), MatchFuzzy, true
+	}
+	if start := []rune(t.key); len(start) > len([]rune(queryKey)) {
+		if d := distance(queryKey, string(start[:len([]rune(queryKey))])); d <= allowed {
+			return 0.5 * (1 - float64(d)/float64(len(queryKey)+1)), MatchFuzzy, true
+		}
+	}
+	return 0, "", false
+}
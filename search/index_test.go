@@ -0,0 +1,64 @@
+package search
+
+import (
+	"taxi-fare-calculator/models"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	index := NewIndex([]models.Station{
+		{Name: "Piassa Station", Slug: "piassa", Aliases: []models.Alias{{Name: "ፒያሳ", Lang: "am"}}},
+		{Name: "Megenagna Station", Slug: "megenagna"},
+		{Name: "Mexico Station", Slug: "mexico"},
+		{Name: "Stadium Station", Slug: "stadium"},
+		{Name: "Bole Station", Slug: "bole"},
+		{Name: "Bole Bulbula Station", Slug: "bole-bulbula"},
+		{Name: "Gofa Station", Slug: "gofa"},
+		{Name: "Goro Station", Slug: "goro"},
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		want  string // empty when the query is not resolved
+		// suggested are stations that must be among the suggestions
+		suggested []string
+	}{
+		{"name", "Piassa Station", "Piassa Station", nil},
+		{"name without suffix", "piassa", "Piassa Station", nil},
+		{"alias", "ፒያሳ", "Piassa Station", nil},
+		{"spelling", "Piasa", "Piassa Station", nil},
+		{"typo", "Megenanga", "Megenagna Station", nil},
+		{"exact name over a longer one", "Bole", "Bole Station", nil},
+		{"slug", "bole-bulbula", "Bole Bulbula Station", nil},
+		{"clear prefix", "Stad", "Stadium Station", nil},
+		{"too short to guess", "St", "", []string{"Stadium Station"}},
+		{"equally close typos", "Gora", "", []string{"Gofa Station", "Goro Station"}},
+		{"no match", "Kality", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			station, suggestions, ok := index.Resolve(tt.query)
+			if tt.want != "" {
+				if !ok || station.Name != tt.want {
+					t.Fatalf("Resolve(%q) = %q, %t, want %q", tt.query, station.Name, ok, tt.want)
+				}
+				return
+			}
+			if ok {
+				t.Fatalf("Resolve(%q) = %q, want no station", tt.query, station.Name)
+			}
+			for _, name := range tt.suggested {
+				found := false
+				for _, suggestion := range suggestions {
+					if suggestion.Station.Name == name {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Resolve(%q) does not suggest %s", tt.query, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"strings"
+	"taxi-fare-calculator/utils"
+	"unicode"
+)
+
+// stopWords are left out when names are compared, so "Megenagna" matches
+// "Megenagna Station" and its Amharic "መገናኛ ጣቢያ"
+var stopWords = map[string]bool{
+	"station": true,
+	"tabiya":  true,
+}
+
+// phonetic folds Latin spellings that sound the same in Amharic names into
+// one. At each position the first matching pair wins, so "ch" stays "ch"
+// before "c" becomes "k".
+var phonetic = strings.NewReplacer(
+	"ch", "ch",
+	"sh", "sh",
+	"ph", "f",
+	"gn", "ny",
+	"ck", "k",
+	"x", "ks",
+	"c", "k",
+	"q", "k",
+	"iy", "i",
+)
+
+// fold romanizes a name into lower-case words, without stop words
+func fold(name string) string {
+	words := strings.FieldsFunc(utils.Romanize(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := words[:0]
+	for _, word := range words {
+		if !stopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		// A name made only of stop words is still a name
+		return strings.Join(words, " ")
+	}
+	return strings.Join(kept, " ")
+}
+
+// key reduces a folded name to how it sounds: spaces are dropped, spellings
+// of the same sound are folded and doubled letters collapse, so "Piassa",
+// "Piasa" and "ፒያሳ" share a key
+func key(folded string) string {
+	spelled := phonetic.Replace(strings.ReplaceAll(folded, " ", ""))
+	var b strings.Builder
+	var last rune
+	for _, r := range spelled {
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+// distance is the optimal string alignment distance between a and b: the
+// number of insertions, deletions, substitutions and swaps of adjacent
+// letters turning one into the other
+func distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(s)][len(t)]
+}
+
+// maxEdits is how many typos are forgiven in a query key of n letters
+func maxEdits(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	case n < 10:
+		return 2
+	default:
+		return 3
+	}
+}
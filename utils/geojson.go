@@ -0,0 +1,32 @@
+package utils
+
+// Point is a GeoJSON point at a [longitude, latitude] position
+type Point struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+func NewPoint(lng, lat float64) *Point {
+	return &Point{Type: "Point", Coordinates: []float64{lng, lat}}
+}
+
+// Feature is a GeoJSON feature
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   interface{}            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+func NewFeature(geometry interface{}, properties map[string]interface{}) Feature {
+	return Feature{Type: "Feature", Geometry: geometry, Properties: properties}
+}
+
+// FeatureCollection is a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"github.com/resendlabs/resend-go"
+)
+
+// Mailer sends HTML emails
+type Mailer interface {
+	Send(to []string, subject, html string) error
+}
+
+// ResendMailer sends emails through the Resend API
+type ResendMailer struct {
+	client *resend.Client
+	from   string
+}
+
+func NewResendMailer(apiKey, from string) *ResendMailer {
+	return &ResendMailer{
+		client: resend.NewClient(apiKey),
+		from:   from,
+	}
+}
+
+func (m *ResendMailer) Send(to []string, subject, html string) error {
+	params := &resend.SendEmailRequest{
+		From:    m.from,
+		To:      to,
+		Subject: subject,
+		Html:    html,
+	}
+	_, err := m.client.Emails.Send(params)
+	return err
+}
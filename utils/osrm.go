@@ -1,44 +1,105 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type OSRMResponse struct {
-	Code   string `json:"code"`
-	Routes []struct {
-		Distance float64 `json:"distance"`
-		Duration float64 `json:"duration"`
-		Geometry string  `json:"geometry"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Routes  []struct {
+		Distance float64    `json:"distance"`
+		Duration float64    `json:"duration"`
+		Geometry LineString `json:"geometry"`
 	} `json:"routes"`
 }
 
-type MapService struct {
-	BaseURL string
+// OSRMRouter finds driving routes with an OSRM server
+type OSRMRouter struct {
+	baseURL string
+	client  *http.Client
+	retries int
 }
 
-func NewMapService() *MapService {
-	return &MapService{
-		BaseURL: "http://router.project-osrm.org", // Using public OSRM instance
+// NewOSRMRouter creates a router for the OSRM server at baseURL. Each request
+// is given up after timeout and failed requests are retried up to retries times.
+func NewOSRMRouter(baseURL string, timeout time.Duration, retries int) *OSRMRouter {
+	return &OSRMRouter{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+		retries: retries,
 	}
 }
 
-func (m *MapService) GetRoute(fromLng, fromLat, toLng, toLat float64) (*OSRMResponse, error) {
+// errRetryable marks failures worth another attempt, such as timeouts and server errors
+var errRetryable = errors.New("temporary OSRM failure")
+
+// Route returns the first driving route OSRM finds between two points
+func (o *OSRMRouter) Route(ctx context.Context, fromLng, fromLat, toLng, toLat float64) (*RoadRoute, error) {
+	var result *OSRMResponse
+	var err error
+	for attempt := 0; attempt <= o.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(attempt) * 200 * time.Millisecond):
+			}
+		}
+		result, err = o.fetch(ctx, fromLng, fromLat, toLng, toLat)
+		if err == nil || !errors.Is(err, errRetryable) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case result.Code == "NoRoute" || (result.Code == "Ok" && len(result.Routes) == 0):
+		return nil, ErrNoRoad
+	case result.Code != "Ok":
+		return nil, fmt.Errorf("OSRM returned %s: %s", result.Code, result.Message)
+	}
+	route := result.Routes[0]
+	return &RoadRoute{
+		Distance: route.Distance,
+		Duration: route.Duration,
+		Geometry: &route.Geometry,
+	}, nil
+}
+
+func (o *OSRMRouter) fetch(ctx context.Context, fromLng, fromLat, toLng, toLat float64) (*OSRMResponse, error) {
 	url := fmt.Sprintf("%s/route/v1/driving/%f,%f;%f,%f?overview=full&geometries=geojson",
-		m.BaseURL, fromLng, fromLat, toLng, toLat)
+		o.baseURL, fromLng, fromLat, toLng, toLat)
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", errRetryable, err)
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("%w: status %d", errRetryable, resp.StatusCode)
+	}
+
+	// OSRM reports failures such as NoRoute with a 400 status and a JSON body
 	var result OSRMResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid OSRM response (status %d): %v", resp.StatusCode, err)
 	}
-
 	return &result, nil
-} 
\ No newline at end of file
+}
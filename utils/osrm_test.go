@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const osrmRoute = `{"code": "Ok", "routes": [{"distance": 2500.5, "duration": 420,
+	"geometry": {"type": "LineString", "coordinates": [[38.745, 9.0107], [38.752, 9.033]]}}]}`
+
+// osrmReply is a status and body an OSRM test server answers with
+type osrmReply struct {
+	status int
+	body   string
+}
+
+func TestOSRMRouter(t *testing.T) {
+	unavailable := osrmReply{http.StatusServiceUnavailable, ""}
+	tests := []struct {
+		name         string
+		retries      int
+		replies      []osrmReply // the last one repeats
+		wantErr      error
+		wantMessage  string
+		wantRequests int
+	}{
+		{"route found", 2, []osrmReply{{http.StatusOK, osrmRoute}}, nil, "", 1},
+		{"no route", 2, []osrmReply{{http.StatusBadRequest, `{"code": "NoRoute", "message": "Impossible route between points"}`}}, ErrNoRoad, "", 1},
+		{"ok without routes", 2, []osrmReply{{http.StatusOK, `{"code": "Ok", "routes": []}`}}, ErrNoRoad, "", 1},
+		{"other codes are not retried", 2, []osrmReply{{http.StatusBadRequest, `{"code": "InvalidQuery", "message": "Query string malformed"}`}}, nil, "OSRM returned InvalidQuery: Query string malformed", 1},
+		{"server errors are retried", 2, []osrmReply{unavailable, {http.StatusBadGateway, ""}, {http.StatusOK, osrmRoute}}, nil, "", 3},
+		{"rate limits are retried", 1, []osrmReply{{http.StatusTooManyRequests, ""}, {http.StatusOK, osrmRoute}}, nil, "", 2},
+		{"retries run out", 1, []osrmReply{unavailable}, errRetryable, "", 2},
+		{"invalid response", 2, []osrmReply{{http.StatusOK, `<html>`}}, nil, "invalid OSRM response (status 200)", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if want := "/route/v1/driving/38.745000,9.010700;38.752000,9.033000"; r.URL.Path != want {
+					t.Errorf("request path = %s, want %s", r.URL.Path, want)
+				}
+				reply := tt.replies[min(requests, len(tt.replies)-1)]
+				requests++
+				w.WriteHeader(reply.status)
+				w.Write([]byte(reply.body))
+			}))
+			defer server.Close()
+
+			router := NewOSRMRouter(server.URL+"/", time.Second, tt.retries)
+			route, err := router.Route(context.Background(), 38.745, 9.0107, 38.752, 9.033)
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+			if tt.wantMessage != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantMessage) {
+					t.Fatalf("Route() error = %v, want %q", err, tt.wantMessage)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Route() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if route.Distance != 2500.5 || route.Duration != 420 || len(route.Geometry.Coordinates) != 2 || route.Approximate {
+				t.Errorf("Route() = %+v, want the route OSRM returned", route)
+			}
+		})
+	}
+}
+
+func TestOSRMRouterCancelledBetweenRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	router := NewOSRMRouter(server.URL, time.Second, 3)
+	if _, err := router.Route(ctx, 38.745, 9.0107, 38.752, 9.033); !errors.Is(err, context.Canceled) {
+		t.Errorf("Route() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+// EncodePolyline encodes [longitude, latitude] positions with Google's
+// encoded polyline algorithm at 5 decimal places
+func EncodePolyline(coordinates [][]float64) string {
+	var b strings.Builder
+	var prevLat, prevLng int64
+	for _, position := range coordinates {
+		if len(position) < 2 {
+			continue
+		}
+		lat := int64(math.Round(position[1] * 1e5))
+		lng := int64(math.Round(position[0] * 1e5))
+		encodeValue(&b, lat-prevLat)
+		encodeValue(&b, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return b.String()
+}
+
+func encodeValue(b *strings.Builder, value int64) {
+	v := value << 1
+	if value < 0 {
+		v = ^v
+	}
+	for v >= 0x20 {
+		b.WriteByte(byte((0x20 | (v & 0x1f)) + 63))
+		v >>= 5
+	}
+	b.WriteByte(byte(v + 63))
+}
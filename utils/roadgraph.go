@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+const (
+	// maxSnapKm is how far a point may be from the nearest road node to be routed
+	maxSnapKm = 1.0
+	// cellDegrees is the size of the grid cells road nodes are indexed by for snapping
+	cellDegrees = 0.01
+	// kmPerDegree is the length of a degree of latitude, rounded down
+	kmPerDegree = 110.5
+	// cancelCheckInterval is how many nodes the path search visits between
+	// checks for a cancelled request
+	cancelCheckInterval = 1024
+)
+
+// LocalRouter routes over a road network held in memory, loaded from a
+// GeoJSON file of LineString or MultiLineString road features. Roads meet
+// where they share a vertex. A feature may set "oneway" to true or "yes" and
+// "maxspeed" to its speed in km/h.
+type LocalRouter struct {
+	nodes [][2]float64 // longitude, latitude
+	edges [][]roadEdge
+	cells map[[2]int][]int // nodes by grid cell
+}
+
+type roadEdge struct {
+	to    int
+	km    float64
+	hours float64
+}
+
+// LoadLocalRouter reads a road network from a GeoJSON file
+func LoadLocalRouter(path string) (*LocalRouter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return NewLocalRouter(file)
+}
+
+// NewLocalRouter reads a road network from GeoJSON
+func NewLocalRouter(r io.Reader) (*LocalRouter, error) {
+	var collection struct {
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("invalid road network: %v", err)
+	}
+
+	router := &LocalRouter{cells: make(map[[2]int][]int)}
+	index := make(map[string]int)
+	node := func(position []float64) int {
+		key := fmt.Sprintf("%.6f,%.6f", position[0], position[1])
+		if i, ok := index[key]; ok {
+			return i
+		}
+		n := len(router.nodes)
+		index[key] = n
+		router.nodes = append(router.nodes, [2]float64{position[0], position[1]})
+		router.edges = append(router.edges, nil)
+		cell := cellOf(position[0], position[1])
+		router.cells[cell] = append(router.cells[cell], n)
+		return n
+	}
+
+	for i, feature := range collection.Features {
+		var lines [][][]float64
+		switch feature.Geometry.Type {
+		case "LineString":
+			var line [][]float64
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &line); err != nil {
+				return nil, fmt.Errorf("invalid road #%d: %v", i+1, err)
+			}
+			lines = append(lines, line)
+		case "MultiLineString":
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &lines); err != nil {
+				return nil, fmt.Errorf("invalid road #%d: %v", i+1, err)
+			}
+		default:
+			continue
+		}
+
+		oneway := false
+		switch v := feature.Properties["oneway"].(type) {
+		case bool:
+			oneway = v
+		case string:
+			oneway = v == "yes" || v == "true"
+		}
+		speed := averageSpeedKmh
+		switch v := feature.Properties["maxspeed"].(type) {
+		case float64:
+			speed = v
+		case string:
+			if s, err := strconv.ParseFloat(v, 64); err == nil {
+				speed = s
+			}
+		}
+		if speed <= 0 {
+			speed = averageSpeedKmh
+		}
+
+		for _, line := range lines {
+			for j := 1; j < len(line); j++ {
+				if len(line[j-1]) < 2 || len(line[j]) < 2 {
+					return nil, fmt.Errorf("invalid road #%d: positions need a longitude and latitude", i+1)
+				}
+				a, b := node(line[j-1]), node(line[j])
+				km := router.distance(a, b)
+				router.edges[a] = append(router.edges[a], roadEdge{to: b, km: km, hours: km / speed})
+				if !oneway {
+					router.edges[b] = append(router.edges[b], roadEdge{to: a, km: km, hours: km / speed})
+				}
+			}
+		}
+	}
+
+	if len(router.nodes) == 0 {
+		return nil, fmt.Errorf("road network has no roads")
+	}
+	return router, nil
+}
+
+// Route returns the shortest way along the roads between the road nodes
+// nearest to two points, joined to the points by straight lines
+func (l *LocalRouter) Route(ctx context.Context, fromLng, fromLat, toLng, toLat float64) (*RoadRoute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	start, startKm := l.nearest(fromLng, fromLat)
+	end, endKm := l.nearest(toLng, toLat)
+	if startKm > maxSnapKm || endKm > maxSnapKm {
+		return nil, fmt.Errorf("%w: point is more than %g km from the road network", ErrNoRoad, maxSnapKm)
+	}
+
+	path, km, hours, err := l.shortestPath(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	coordinates := [][]float64{{fromLng, fromLat}}
+	for _, n := range path {
+		coordinates = append(coordinates, []float64{l.nodes[n][0], l.nodes[n][1]})
+	}
+	coordinates = append(coordinates, []float64{toLng, toLat})
+
+	km += startKm + endKm
+	hours += (startKm + endKm) / averageSpeedKmh
+	return &RoadRoute{
+		Distance: km * 1000,
+		Duration: hours * 3600,
+		Geometry: &LineString{Type: "LineString", Coordinates: coordinates},
+	}, nil
+}
+
+// nearest returns the road node closest to a point and its distance in
+// kilometers. Only the grid cells within maxSnapKm are searched, so a point
+// farther from every node gets an infinite distance.
+func (l *LocalRouter) nearest(lng, lat float64) (int, float64) {
+	cell := cellOf(lng, lat)
+	latCells := int(math.Ceil(maxSnapKm / (kmPerDegree * cellDegrees)))
+	lngCells := latCells
+	if cos := math.Cos(lat * math.Pi / 180); cos > 0 {
+		lngCells = int(math.Ceil(maxSnapKm / (kmPerDegree * cos * cellDegrees)))
+	}
+
+	best, bestKm := -1, math.Inf(1)
+	for x := cell[0] - lngCells; x <= cell[0]+lngCells; x++ {
+		for y := cell[1] - latCells; y <= cell[1]+latCells; y++ {
+			for _, i := range l.cells[[2]int{x, y}] {
+				n := l.nodes[i]
+				if km := HaversineDistance(lat, lng, n[1], n[0]); km < bestKm {
+					best, bestKm = i, km
+				}
+			}
+		}
+	}
+	return best, bestKm
+}
+
+// cellOf returns the grid cell of a position
+func cellOf(lng, lat float64) [2]int {
+	return [2]int{int(math.Floor(lng / cellDegrees)), int(math.Floor(lat / cellDegrees))}
+}
+
+func (l *LocalRouter) distance(a, b int) float64 {
+	return HaversineDistance(l.nodes[a][1], l.nodes[a][0], l.nodes[b][1], l.nodes[b][0])
+}
+
+// shortestPath finds the shortest road path between two nodes using
+// Dijkstra's algorithm, giving up when the context is done
+func (l *LocalRouter) shortestPath(ctx context.Context, from, to int) ([]int, float64, float64, error) {
+	distances := map[int]float64{from: 0}
+	hours := map[int]float64{from: 0}
+	previous := make(map[int]int)
+	visited := make(map[int]bool)
+
+	queue := &roadQueue{{node: from}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(roadItem)
+		if visited[current.node] {
+			continue
+		}
+		visited[current.node] = true
+		if current.node == to {
+			break
+		}
+		if len(visited)%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, 0, 0, err
+			}
+		}
+		for _, e := range l.edges[current.node] {
+			if visited[e.to] {
+				continue
+			}
+			km := current.km + e.km
+			if known, seen := distances[e.to]; !seen || km < known {
+				distances[e.to] = km
+				hours[e.to] = hours[current.node] + e.hours
+				previous[e.to] = current.node
+				heap.Push(queue, roadItem{node: e.to, km: km})
+			}
+		}
+	}
+
+	if !visited[to] {
+		return nil, 0, 0, ErrNoRoad
+	}
+	path := []int{to}
+	for current := to; current != from; {
+		current = previous[current]
+		path = append([]int{current}, path...)
+	}
+	return path, distances[to], hours[to], nil
+}
+
+type roadItem struct {
+	node int
+	km   float64
+}
+
+// roadQueue is a min-heap of road nodes ordered by distance
+type roadQueue []roadItem
+
+func (q roadQueue) Len() int            { return len(q) }
+func (q roadQueue) Less(i, j int) bool  { return q[i].km < q[j].km }
+func (q roadQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *roadQueue) Push(x interface{}) { *q = append(*q, x.(roadItem)) }
+func (q *roadQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	*q = old[:n-1]
+	return it
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// roadNetwork has a two-way road from A to B to C at 40 km/h, a one-way road
+// from C to D, and a road between E and F that meets none of the others
+const roadNetwork = `{"type": "FeatureCollection", "features": [
+	{"type": "Feature", "properties": {"maxspeed": "40"},
+	 "geometry": {"type": "LineString", "coordinates": [[38.740, 9.000], [38.750, 9.000], [38.750, 9.010]]}},
+	{"type": "Feature", "properties": {"oneway": "yes"},
+	 "geometry": {"type": "LineString", "coordinates": [[38.750, 9.010], [38.760, 9.010]]}},
+	{"type": "Feature", "properties": {},
+	 "geometry": {"type": "MultiLineString", "coordinates": [[[38.800, 9.050], [38.805, 9.050]]]}},
+	{"type": "Feature", "properties": {},
+	 "geometry": {"type": "Point", "coordinates": [38.700, 9.000]}}
+]}`
+
+func newTestRouter(t *testing.T) *LocalRouter {
+	t.Helper()
+	router, err := NewLocalRouter(strings.NewReader(roadNetwork))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func TestLocalRouterRoute(t *testing.T) {
+	router := newTestRouter(t)
+	ab := HaversineDistance(9.000, 38.740, 9.000, 38.750)
+	bc := HaversineDistance(9.000, 38.750, 9.010, 38.750)
+	cd := HaversineDistance(9.010, 38.750, 9.010, 38.760)
+	snap := HaversineDistance(9.0003, 38.7403, 9.000, 38.740)
+
+	tests := []struct {
+		name    string
+		from    [2]float64
+		to      [2]float64
+		wantKm  float64
+		wantErr error
+	}{
+		{"along the roads", [2]float64{38.740, 9.000}, [2]float64{38.750, 9.010}, ab + bc, nil},
+		{"snaps to the nearest road node", [2]float64{38.7403, 9.0003}, [2]float64{38.750, 9.010}, snap + ab + bc, nil},
+		{"one-way road forwards", [2]float64{38.750, 9.010}, [2]float64{38.760, 9.010}, cd, nil},
+		{"one-way road backwards", [2]float64{38.760, 9.010}, [2]float64{38.750, 9.010}, 0, ErrNoRoad},
+		{"roads that never meet", [2]float64{38.740, 9.000}, [2]float64{38.800, 9.050}, 0, ErrNoRoad},
+		{"point far from every road", [2]float64{38.740, 9.000}, [2]float64{38.740, 9.100}, 0, ErrNoRoad},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := router.Route(context.Background(), tt.from[0], tt.from[1], tt.to[0], tt.to[1])
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Route() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if math.Abs(route.Distance-tt.wantKm*1000) > 1e-6 {
+				t.Errorf("Distance = %v m, want %v m", route.Distance, tt.wantKm*1000)
+			}
+			coordinates := route.Geometry.Coordinates
+			if first, last := coordinates[0], coordinates[len(coordinates)-1]; !reflect.DeepEqual(first, []float64{tt.from[0], tt.from[1]}) || !reflect.DeepEqual(last, []float64{tt.to[0], tt.to[1]}) {
+				t.Errorf("geometry runs from %v to %v, want the requested points", first, last)
+			}
+		})
+	}
+}
+
+func TestLocalRouterSpeeds(t *testing.T) {
+	router := newTestRouter(t)
+
+	// A to C runs at the road's 40 km/h, C to D at the assumed average speed
+	route, err := router.Route(context.Background(), 38.740, 9.000, 38.750, 9.010)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := route.Distance / 1000 / 40 * 3600; math.Abs(route.Duration-want) > 1e-6 {
+		t.Errorf("Duration = %v s, want %v s", route.Duration, want)
+	}
+	route, err = router.Route(context.Background(), 38.750, 9.010, 38.760, 9.010)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := route.Distance / 1000 / averageSpeedKmh * 3600; math.Abs(route.Duration-want) > 1e-6 {
+		t.Errorf("Duration = %v s, want %v s", route.Duration, want)
+	}
+}
+
+func TestLocalRouterNearest(t *testing.T) {
+	router := newTestRouter(t)
+
+	// The grid finds the node a full scan finds for every point within snapping distance
+	for lng := 38.730; lng <= 38.815; lng += 0.0017 {
+		for lat := 8.990; lat <= 9.060; lat += 0.0013 {
+			want, wantKm := -1, math.Inf(1)
+			for i, n := range router.nodes {
+				if km := HaversineDistance(lat, lng, n[1], n[0]); km < wantKm {
+					want, wantKm = i, km
+				}
+			}
+			got, gotKm := router.nearest(lng, lat)
+			if wantKm > maxSnapKm {
+				if gotKm <= maxSnapKm {
+					t.Errorf("nearest(%.4f, %.4f) = node %d at %.3f km, want none within %g km", lng, lat, got, gotKm, maxSnapKm)
+				}
+				continue
+			}
+			if got != want {
+				t.Errorf("nearest(%.4f, %.4f) = node %d at %.3f km, want node %d at %.3f km", lng, lat, got, gotKm, want, wantKm)
+			}
+		}
+	}
+}
+
+func TestLocalRouterCancelled(t *testing.T) {
+	router := newTestRouter(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := router.Route(ctx, 38.740, 9.000, 38.750, 9.010); !errors.Is(err, context.Canceled) {
+		t.Errorf("Route() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestNewLocalRouterInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+	}{
+		{"not JSON", `roads`},
+		{"no roads", `{"type": "FeatureCollection", "features": []}`},
+		{"position without latitude", `{"features": [{"geometry": {"type": "LineString", "coordinates": [[38.74], [38.75, 9.0]]}}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLocalRouter(strings.NewReader(tt.network)); err == nil {
+				t.Error("NewLocalRouter() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// ErrNoRoad is returned when a router cannot find a road between two points
+var ErrNoRoad = errors.New("no road route found")
+
+// averageSpeedKmh is the speed assumed when a router has no travel times
+const averageSpeedKmh = 20.0
+
+// Router finds routes along roads between two points given as longitude and latitude
+type Router interface {
+	Route(ctx context.Context, fromLng, fromLat, toLng, toLat float64) (*RoadRoute, error)
+}
+
+// RoadRoute is a way along roads between two points
+type RoadRoute struct {
+	Distance float64     `json:"distance"` // meters
+	Duration float64     `json:"duration"` // seconds
+	Geometry *LineString `json:"geometry,omitempty"`
+	// Approximate is set when the route was not found on the road network
+	Approximate bool `json:"approximate,omitempty"`
+}
+
+// LineString is a GeoJSON line of [longitude, latitude] positions
+type LineString struct {
+	Type        string      `json:"type"`
+	Coordinates [][]float64 `json:"coordinates"`
+}
+
+// FallbackRouter asks each router in turn until one finds a route
+type FallbackRouter []Router
+
+func (f FallbackRouter) Route(ctx context.Context, fromLng, fromLat, toLng, toLat float64) (*RoadRoute, error) {
+	err := ErrNoRoad
+	for i, router := range f {
+		var route *RoadRoute
+		route, err = router.Route(ctx, fromLng, fromLat, toLng, toLat)
+		if err == nil {
+			return route, nil
+		}
+		if i < len(f)-1 {
+			log.Printf("⚠️ Road routing failed, trying the next router: %v", err)
+		}
+	}
+	return nil, err
+}
+
+// StraightLineRouter approximates road routes by the straight-line distance
+// stretched by a detour factor, for when no road network is available
+type StraightLineRouter struct {
+	DetourFactor float64
+}
+
+func (s StraightLineRouter) Route(ctx context.Context, fromLng, fromLat, toLng, toLat float64) (*RoadRoute, error) {
+	factor := s.DetourFactor
+	if factor < 1 {
+		factor = 1
+	}
+	km := HaversineDistance(fromLat, fromLng, toLat, toLng) * factor
+	return &RoadRoute{
+		Distance: km * 1000,
+		Duration: km / averageSpeedKmh * 3600,
+		Geometry: &LineString{
+			Type:        "LineString",
+			Coordinates: [][]float64{{fromLng, fromLat}, {toLng, toLat}},
+		},
+		Approximate: true,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// stubRouter answers every request with the same route or error, counting them
+type stubRouter struct {
+	route    *RoadRoute
+	err      error
+	requests int
+}
+
+func (s *stubRouter) Route(ctx context.Context, fromLng, fromLat, toLng, toLat float64) (*RoadRoute, error) {
+	s.requests++
+	return s.route, s.err
+}
+
+func TestFallbackRouter(t *testing.T) {
+	road := &RoadRoute{Distance: 3100, Duration: 600}
+	straight := HaversineDistance(9.0107, 38.745, 9.033, 38.752) * 1.4 * 1000
+
+	t.Run("first router with a route wins", func(t *testing.T) {
+		first, second := &stubRouter{route: road}, &stubRouter{err: ErrNoRoad}
+		route, err := FallbackRouter{first, second}.Route(context.Background(), 38.745, 9.0107, 38.752, 9.033)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if route != road || second.requests != 0 {
+			t.Errorf("Route() = %+v after %d fallback requests, want the first router's route", route, second.requests)
+		}
+	})
+
+	t.Run("falls back to a straight line", func(t *testing.T) {
+		osrm, local := &stubRouter{err: errors.New("OSRM unreachable")}, &stubRouter{err: ErrNoRoad}
+		router := FallbackRouter{osrm, local, StraightLineRouter{DetourFactor: 1.4}}
+		route, err := router.Route(context.Background(), 38.745, 9.0107, 38.752, 9.033)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if osrm.requests != 1 || local.requests != 1 {
+			t.Errorf("routers asked %d and %d times, want once each", osrm.requests, local.requests)
+		}
+		if !route.Approximate || math.Abs(route.Distance-straight) > 1e-6 {
+			t.Errorf("Route() = %+v, want an approximate %v m route", route, straight)
+		}
+	})
+
+	t.Run("last error when every router fails", func(t *testing.T) {
+		last := errors.New("road network unavailable")
+		_, err := FallbackRouter{&stubRouter{err: ErrNoRoad}, &stubRouter{err: last}}.Route(context.Background(), 38.745, 9.0107, 38.752, 9.033)
+		if err != last {
+			t.Errorf("Route() error = %v, want %v", err, last)
+		}
+	})
+
+	t.Run("no routers", func(t *testing.T) {
+		if _, err := (FallbackRouter{}).Route(context.Background(), 38.745, 9.0107, 38.752, 9.033); !errors.Is(err, ErrNoRoad) {
+			t.Errorf("Route() error = %v, want %v", err, ErrNoRoad)
+		}
+	})
+}
+
+func TestStraightLineRouter(t *testing.T) {
+	km := HaversineDistance(9.0107, 38.745, 9.033, 38.752)
+	tests := []struct {
+		name   string
+		detour float64
+		wantKm float64
+	}{
+		{"detour factor stretches the line", 1.4, km * 1.4},
+		{"detour factor below one is ignored", 0.5, km},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := StraightLineRouter{DetourFactor: tt.detour}.Route(context.Background(), 38.745, 9.0107, 38.752, 9.033)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(route.Distance-tt.wantKm*1000) > 1e-6 {
+				t.Errorf("Distance = %v m, want %v m", route.Distance, tt.wantKm*1000)
+			}
+			if want := tt.wantKm / averageSpeedKmh * 3600; math.Abs(route.Duration-want) > 1e-6 {
+				t.Errorf("Duration = %v s, want %v s", route.Duration, want)
+			}
+		})
+	}
+}
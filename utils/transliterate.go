@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ethiopicRows are the consonants of the Ethiopic syllabary, one per row of
+// eight code points from U+1200. An empty consonant is a vowel carrier.
+var ethiopicRows = []string{
+	"h", "l", "h", "m", "s", "r", "s", "sh", "q", "qw", "q", "qw", "b", "v", "t", "ch",
+	"h", "hw", "n", "ny", "", "k", "kw", "h", "hw", "w", "", "z", "zh", "y", "d", "d",
+	"j", "g", "gw", "ng", "t", "ch", "p", "ts", "ts", "f", "p",
+}
+
+// ethiopicVowels are the vowels of the seven orders, and the labialized eighth.
+// The sixth order is usually silent in Latin spellings.
+var ethiopicVowels = []string{"e", "u", "i", "a", "e", "", "o", "wa"}
+
+// latinFolds spells accented letters the way they are commonly typed without accents
+var latinFolds = map[rune]string{
+	'á': "a", 'à': "a", 'â': "a", 'ä': "e", 'ã': "a", 'å': "a", 'ā': "a",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ě': "e", 'ə': "e",
+	'í': "i", 'ì': "i", 'î': "i", 'ï': "i", 'ī': "i",
+	'ó': "o", 'ò': "o", 'ô': "o", 'ö': "o", 'õ': "o", 'ō': "o",
+	'ú': "u", 'ù': "u", 'û': "u", 'ü': "u", 'ū': "u",
+	'ç': "c", 'ñ': "ny", 'š': "sh", 'ž': "zh", 'č': "ch", 'ǧ': "g", 'ß': "ss",
+	// Apostrophes mark ejectives and glottal stops, which are typed without them
+	'\'': "", '’': "", 'ʼ': "", 'ʾ': "", 'ʿ': "",
+}
+
+// Romanize returns a lower-case Latin spelling of s: Ethiopic script is
+// transliterated and accented Latin letters lose their accents, so
+// "መገናኛ" and "Megenaña" both come out as "megenanya"
+func Romanize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 0x1200 && int(r-0x1200) < len(ethiopicRows)*8:
+			consonant := ethiopicRows[(r-0x1200)/8]
+			vowel := ethiopicVowels[(r-0x1200)%8]
+			if consonant == "" && vowel == "" {
+				vowel = "e"
+			} else if consonant == "" && (r-0x1200)%8 == 0 {
+				vowel = "a"
+			}
+			b.WriteString(consonant + vowel)
+		case r == '፡' || r == '።' || r == '፣' || r == '፤':
+			b.WriteByte(' ')
+		default:
+			if folded, ok := latinFolds[r]; ok {
+				b.WriteString(folded)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Slugify turns a name into a lower-case identifier of ASCII letters, digits and hyphens
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range Romanize(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}